@@ -0,0 +1,425 @@
+// Package assembler turns mnemonic source text into bytecode for the virtual machine.
+//
+// Every line holds at most one instruction, optionally preceded by a label and followed by a comment:
+//
+//	loop:   push-int 5          ; immediate operant
+//	        get-byte (data)     ; absolute address, number or label
+//	        put-int  {-8}       ; address relative to the stack-pointer
+//	        jmpz-int (loop)
+//	data:   .byte 1, 2, 3       ; raw data
+//	name:   .string "hi; there" ; length followed by the UTF-8 bytes, for push-string
+package assembler
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+// -- Source statements ---------------------------------------------------------------------------------------------------------
+
+// statement is a single parsed instruction or directive, together with where it came from
+type statement struct {
+	line    int
+	column  int
+	address int
+
+	code     *virtualmachine.Instruction // nil for directives
+	mnemonic string
+	operants []operant
+}
+
+// operant is a single (unresolved) operant of a statement
+type operant struct {
+	column int
+	text   string
+}
+
+// size returns the number of bytes the statement takes in memory
+func (s *statement) size() int {
+	if s.code != nil {
+		return s.code.Size()
+	}
+
+	switch s.mnemonic {
+	case ".byte":
+		return len(s.operants)
+	case ".int":
+		return len(s.operants) * intSize
+	case ".float":
+		return len(s.operants) * floatSize
+	case ".string":
+		value, _ := strconv.Unquote(s.operants[0].text)
+		return intSize + len(value)
+	}
+
+	return 0
+}
+
+// -- Assembler -----------------------------------------------------------------------------------------------------------------
+
+type assembler struct {
+	origin     int // address the program is loaded at
+	statements []*statement
+	labels     map[string]int
+	output     []byte
+}
+
+// Assemble translates source text into a program that can be loaded by the virtual machine at address 0
+func Assemble(source string) ([]byte, error) {
+	return AssembleAt(source, 0)
+}
+
+// AssembleAt translates source text into a program that can be loaded by the virtual machine at address origin, the
+// Config.LoadAddress. Labels get the addresses they have once the program is loaded.
+func AssembleAt(source string, origin int) ([]byte, error) {
+	if origin < 0 {
+		return nil, fmt.Errorf("illegal origin %d", origin)
+	}
+
+	asm := &assembler{origin: origin, labels: make(map[string]int)}
+
+	err := asm.parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	err = asm.emit()
+	if err != nil {
+		return nil, err
+	}
+
+	return asm.output, nil
+}
+
+// parse runs the first pass: it splits the source into statements, assigns addresses and collects the labels
+func (asm *assembler) parse(source string) error {
+	address := asm.origin
+
+	for i, text := range strings.Split(source, "\n") {
+		line := i + 1
+
+		// Remove comments
+		if pos := commentStart(text); pos >= 0 {
+			text = text[:pos]
+		}
+		text = strings.TrimRightFunc(text, unicode.IsSpace)
+
+		column := skipSpace(text, 0)
+
+		// Labels
+		for column < len(text) {
+			end := tokenEnd(text, column)
+			if end == column || text[end-1] != ':' {
+				break
+			}
+
+			label := text[column : end-1]
+			if !isIdentifier(label) {
+				return errorAt(line, column+1, "illegal label %q", label)
+			}
+			if _, found := asm.labels[label]; found {
+				return errorAt(line, column+1, "label %q already defined", label)
+			}
+			asm.labels[label] = address
+
+			column = skipSpace(text, end)
+		}
+
+		if column >= len(text) {
+			continue
+		}
+
+		// Mnemonic
+		end := tokenEnd(text, column)
+		st := &statement{line: line, column: column + 1, address: address, mnemonic: text[column:end]}
+
+		// Operants
+		offset := skipSpace(text, end)
+		if offset < len(text) && st.mnemonic == ".string" {
+			// A single operant that may hold commas
+			st.operants = append(st.operants, operant{column: offset + 1, text: text[offset:]})
+		} else if offset < len(text) {
+			for _, part := range strings.Split(text[offset:], ",") {
+				trimmed := strings.TrimSpace(part)
+				if trimmed == "" {
+					return errorAt(line, offset+1, "missing operant")
+				}
+				st.operants = append(st.operants, operant{column: offset + skipSpace(part, 0) + 1, text: trimmed})
+				offset += len(part) + 1
+			}
+		}
+
+		err := asm.classify(st)
+		if err != nil {
+			return err
+		}
+
+		asm.statements = append(asm.statements, st)
+		address += st.size()
+	}
+
+	return nil
+}
+
+// classify finds the instruction that matches the mnemonic and the form of its operant
+func (asm *assembler) classify(st *statement) error {
+	if strings.HasPrefix(st.mnemonic, ".") {
+		switch st.mnemonic {
+		case ".byte", ".int", ".float":
+			if len(st.operants) == 0 {
+				return errorAt(st.line, st.column, "%s needs at least one value", st.mnemonic)
+			}
+			return nil
+		case ".string":
+			if len(st.operants) == 0 {
+				return errorAt(st.line, st.column, ".string needs a quoted string")
+			}
+			if _, err := strconv.Unquote(st.operants[0].text); err != nil {
+				return errorAt(st.line, st.operants[0].column, "illegal string %s", st.operants[0].text)
+			}
+			return nil
+		}
+		return errorAt(st.line, st.column, "unknown directive %q", st.mnemonic)
+	}
+
+	forms, found := mnemonics[st.mnemonic]
+	if !found {
+		return errorAt(st.line, st.column, "unknown mnemonic %q", st.mnemonic)
+	}
+
+	if len(st.operants) > 1 {
+		return errorAt(st.line, st.operants[1].column, "%s takes at most one operant", st.mnemonic)
+	}
+
+	form := formNone
+	column := st.column
+	if len(st.operants) == 1 {
+		form = formOf(st.operants[0].text)
+		column = st.operants[0].column
+	}
+
+	code, found := forms[form]
+	if !found && form == formNone {
+		return errorAt(st.line, column, "%s needs an operant", st.mnemonic)
+	}
+	if !found {
+		return errorAt(st.line, column, "%s does not take %s", st.mnemonic, form)
+	}
+
+	st.code = code
+	return nil
+}
+
+// emit runs the second pass: it resolves the operants and writes the bytes
+func (asm *assembler) emit() error {
+	for _, st := range asm.statements {
+		if st.code == nil {
+			err := asm.emitDirective(st)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		asm.output = append(asm.output, st.code.Opcode.Bytes()...)
+		if st.code.Operant == virtualmachine.OperantNone {
+			continue
+		}
+
+		op := st.operants[0]
+		text := op.text
+		if st.code.Operant == virtualmachine.OperantAddress || st.code.Operant == virtualmachine.OperantStack {
+			text = strings.TrimSpace(text[1 : len(text)-1])
+		}
+
+		switch st.code.Operant {
+		case virtualmachine.OperantByte:
+			value, err := parseByte(text)
+			if err != nil {
+				return errorAt(st.line, op.column, "%s", err)
+			}
+			asm.output = append(asm.output, value)
+		case virtualmachine.OperantInt, virtualmachine.OperantAddress:
+			value, err := asm.resolveInt(text)
+			if err != nil {
+				return errorAt(st.line, op.column, "%s", err)
+			}
+			asm.writeInt(value)
+		case virtualmachine.OperantStack:
+			value, err := strconv.ParseInt(text, 0, 8*intSize)
+			if err != nil {
+				return errorAt(st.line, op.column, "illegal stack offset %q", text)
+			}
+			asm.writeInt(value)
+		case virtualmachine.OperantInt16:
+			value, err := strconv.ParseInt(text, 0, 16)
+			if err != nil {
+				return errorAt(st.line, op.column, "illegal int16 %q", text)
+			}
+			asm.output = append(asm.output, byte(value), byte(value>>8))
+		case virtualmachine.OperantInt32:
+			value, err := strconv.ParseInt(text, 0, 32)
+			if err != nil {
+				return errorAt(st.line, op.column, "illegal int32 %q", text)
+			}
+			asm.output = append(asm.output, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+		case virtualmachine.OperantFloat:
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return errorAt(st.line, op.column, "illegal float %q", text)
+			}
+			asm.writeFloat(value)
+		}
+	}
+
+	return nil
+}
+
+// emitDirective writes the raw data of a directive
+func (asm *assembler) emitDirective(st *statement) error {
+	if st.mnemonic == ".string" {
+		value, _ := strconv.Unquote(st.operants[0].text)
+		asm.writeInt(int64(len(value)))
+		asm.output = append(asm.output, value...)
+		return nil
+	}
+
+	for _, op := range st.operants {
+		switch st.mnemonic {
+		case ".byte":
+			value, err := parseByte(op.text)
+			if err != nil {
+				return errorAt(st.line, op.column, "%s", err)
+			}
+			asm.output = append(asm.output, value)
+		case ".int":
+			value, err := asm.resolveInt(op.text)
+			if err != nil {
+				return errorAt(st.line, op.column, "%s", err)
+			}
+			asm.writeInt(value)
+		case ".float":
+			value, err := strconv.ParseFloat(op.text, 64)
+			if err != nil {
+				return errorAt(st.line, op.column, "illegal float %q", op.text)
+			}
+			asm.writeFloat(value)
+		}
+	}
+
+	return nil
+}
+
+// resolveInt turns a number or a label into an int, kept at 64 bits whatever the host
+func (asm *assembler) resolveInt(text string) (int64, error) {
+	if isIdentifier(text) {
+		address, found := asm.labels[text]
+		if !found {
+			return 0, fmt.Errorf("undefined label %q", text)
+		}
+		return int64(address), nil
+	}
+
+	value, err := strconv.ParseInt(text, 0, 8*intSize)
+	if err != nil {
+		return 0, fmt.Errorf("illegal int %q", text)
+	}
+
+	return value, nil
+}
+
+// writeInt appends an int in the same layout the virtual machine uses in memory
+func (asm *assembler) writeInt(value int64) {
+	buffer := make([]byte, intSize)
+	binary.LittleEndian.PutUint64(buffer, uint64(value))
+	asm.output = append(asm.output, buffer...)
+}
+
+// writeFloat appends a float in the same layout the virtual machine uses in memory
+func (asm *assembler) writeFloat(value float64) {
+	buffer := make([]byte, floatSize)
+	binary.LittleEndian.PutUint64(buffer, math.Float64bits(value))
+	asm.output = append(asm.output, buffer...)
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// parseByte accepts 0..255 as well as -128..-1
+func parseByte(text string) (byte, error) {
+	value, err := strconv.ParseInt(text, 0, 16)
+	if err != nil || value < -128 || value > 255 {
+		return 0, fmt.Errorf("illegal byte %q", text)
+	}
+
+	return byte(value), nil
+}
+
+// commentStart returns the position of the ; starting a comment, -1 if there is none. A ; in a quoted string doesn't count.
+func commentStart(text string) int {
+	quoted := false
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quoted && text[i] == '\\':
+			i++
+		case text[i] == '"':
+			quoted = !quoted
+		case !quoted && text[i] == ';':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// formOf determines the syntactic form of an operant
+func formOf(text string) operantForm {
+	switch {
+	case len(text) >= 2 && text[0] == '(' && text[len(text)-1] == ')':
+		return formAddress
+	case len(text) >= 2 && text[0] == '{' && text[len(text)-1] == '}':
+		return formStack
+	}
+
+	return formImmediate
+}
+
+func isIdentifier(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for i, r := range text {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func skipSpace(text string, from int) int {
+	for from < len(text) && (text[from] == ' ' || text[from] == '\t' || text[from] == '\r') {
+		from++
+	}
+	return from
+}
+
+func tokenEnd(text string, from int) int {
+	for from < len(text) && text[from] != ' ' && text[from] != '\t' && text[from] != '\r' {
+		from++
+	}
+	return from
+}
+
+func errorAt(line int, column int, format string, v ...interface{}) error {
+	return fmt.Errorf("line %d, column %d: %s", line, column, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,260 @@
+package assembler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+func intBytes(value int) []byte {
+	buffer := make([]byte, intSize)
+	binary.LittleEndian.PutUint64(buffer, uint64(int64(value)))
+	return buffer
+}
+
+func floatBytes(value float64) []byte {
+	buffer := make([]byte, floatSize)
+	binary.LittleEndian.PutUint64(buffer, math.Float64bits(value))
+	return buffer
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// -- Tests ---------------------------------------------------------------------------------------------------------------------
+
+func TestAssembleOperants(t *testing.T) {
+	source := `
+		push-byte  0x20
+		push-int   -325
+		push-float 12.5
+		get-byte   (16)
+		put-int    {-8}
+		add-int
+		end`
+
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := join(
+		[]byte{0x08, 0x20},
+		[]byte{0x09}, intBytes(-325),
+		[]byte{0x0A}, floatBytes(12.5),
+		[]byte{0x20}, intBytes(16),
+		[]byte{0x39}, intBytes(-8),
+		[]byte{0x41},
+		[]byte{0x00})
+	if !bytes.Equal(program, expected) {
+		t.Errorf("expected % X, got % X", expected, program)
+	}
+}
+
+func TestAssembleExtended(t *testing.T) {
+	source := `
+		push-int16 -2
+		push-int32 0x12345678
+		get-int16  (16)
+		int16-to-int
+		end`
+
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := join(
+		[]byte{0xFF, 0x08, 0xFE, 0xFF},
+		[]byte{0xFF, 0x09, 0x78, 0x56, 0x34, 0x12},
+		[]byte{0xFF, 0x20}, intBytes(16),
+		[]byte{0xFF, 0x80},
+		[]byte{0x00})
+	if !bytes.Equal(program, expected) {
+		t.Errorf("expected % X, got % X", expected, program)
+	}
+
+	_, err = Assemble("push-int16 40000")
+	if err == nil || err.Error() != `line 1, column 12: illegal int16 "40000"` {
+		t.Errorf("expected illegal int16, got %v", err)
+	}
+}
+
+func TestAssembleString(t *testing.T) {
+	source := `
+		push-string (greeting)
+		length-string      ; 13 bytes
+		end
+greeting: .string "héllo; \"you\"" ; quotes, escapes and a ; in the string`
+
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	greeting := 1 + intSize + 1 + 1
+	expected := join(
+		[]byte{0xB0}, intBytes(greeting),
+		[]byte{0xB1},
+		[]byte{0x00},
+		intBytes(13), []byte("héllo; \"you\""))
+	if !bytes.Equal(program, expected) {
+		t.Errorf("expected % X, got % X", expected, program)
+	}
+
+	vm, err := virtualmachine.NewVirtualMachine(256, 64)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(program)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	length, err := vm.Stack().PopInt()
+	if err != nil || length != 13 {
+		t.Errorf("Expected a length of 13, got %d (%v)", length, err)
+	}
+}
+
+func TestAssembleLabels(t *testing.T) {
+	source := `
+start:	jmp (forward)      ; forward reference
+back:	end
+forward:
+		push-int back       ; label as immediate
+		call (start)
+data:	.byte 1, 2
+		.int data`
+
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	back := 1 + intSize
+	forward := back + 1
+	data := forward + 1 + intSize + 1 + intSize
+
+	expected := join(
+		[]byte{0xE1}, intBytes(forward),
+		[]byte{0x00},
+		[]byte{0x09}, intBytes(back),
+		[]byte{0xF9}, intBytes(0),
+		[]byte{0x01, 0x02},
+		intBytes(data))
+	if !bytes.Equal(program, expected) {
+		t.Errorf("expected % X, got % X", expected, program)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		error  string
+	}{
+		{"push-int 1\n  bogus", "line 2, column 3: unknown mnemonic \"bogus\""},
+		{"push-byte 300", "line 1, column 11: illegal byte \"300\""},
+		{"add-int 5", "line 1, column 9: add-int does not take an immediate operant"},
+		{"push-int {5}", "line 1, column 10: push-int does not take a {stack} operant"},
+		{"jmp", "line 1, column 1: jmp needs an operant"},
+		{"  jmpz-int (nowhere)", "line 1, column 12: undefined label \"nowhere\""},
+		{"a: end\na: end", "line 2, column 1: label \"a\" already defined"},
+		{"push-int 1, 2", "line 1, column 13: push-int takes at most one operant"},
+		{".word 1", "line 1, column 1: unknown directive \".word\""},
+		{".string", "line 1, column 1: .string needs a quoted string"},
+		{".string \"open", "line 1, column 9: illegal string \"open"},
+		{".string 'a', 'b'", "line 1, column 9: illegal string 'a', 'b'"},
+	}
+
+	for _, test := range tests {
+		_, err := Assemble(test.source)
+		if err == nil {
+			t.Errorf("%q: expected %s", test.source, test.error)
+			continue
+		}
+		if err.Error() != test.error {
+			t.Errorf("%q: expected %s, got %s", test.source, test.error, err.Error())
+		}
+	}
+}
+
+func TestAssembleRun(t *testing.T) {
+	// Counts down from 3 to 0 through a subroutine, storing the counter in memory
+	source := strings.Join([]string{
+		"loop:   get-int (counter)",
+		"        call (decrement)",
+		"        put-int (counter)",
+		"        get-int (counter)",
+		"        jmpnz-int (loop)",
+		"        end",
+		"decrement:",
+		"        get-int {-16}        ; fetch the argument below the return address",
+		"        push-int 1",
+		"        sub-int",
+		"        put-int {-16}",
+		"        ret",
+		"counter: .int 3",
+	}, "\n")
+
+	for _, origin := range []int{0, 64} {
+		program, err := AssembleAt(source, origin)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		cfg := virtualmachine.DefaultConfig(256, 64)
+		cfg.LoadAddress = origin
+		vm, err := virtualmachine.NewVirtualMachineWithConfig(cfg)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		err = vm.Load(program)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		err = vm.Run()
+		if err != nil {
+			t.Errorf("origin %d: %v", origin, err)
+			continue
+		}
+
+		counter, err := vm.Memory().GetInt(origin + len(program) - intSize)
+		if err != nil || counter != 0 {
+			t.Errorf("origin %d: expected the counter to reach 0, got %d (%v)", origin, counter, err)
+		}
+		if vm.Stack().Pointer() != 0 {
+			t.Errorf("origin %d: expected an empty stack, got %d bytes", origin, vm.Stack().Pointer())
+		}
+	}
+}
+
+func TestAssembleOrigin(t *testing.T) {
+	program, err := AssembleAt("start: jmp (start)\nend", 0x40)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := join([]byte{0xE1}, intBytes(0x40), []byte{0x00})
+	if !bytes.Equal(program, expected) {
+		t.Errorf("expected % X, got % X", expected, program)
+	}
+
+	_, err = AssembleAt("end", -1)
+	if err == nil {
+		t.Errorf("expected an error for a negative origin")
+	}
+}
@@ -0,0 +1,84 @@
+package assembler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+// Line is a single decoded instruction, or a single byte of data when it doesn't decode
+type Line struct {
+	Address int
+	Bytes   []byte
+	Text    string
+	Data    bool // unknown opcode or an operant running past the end
+}
+
+// rawWidth is the width of the raw bytes column, wide enough for an extended opcode with an int or float operant
+var rawWidth = 3*(2+intSize) - 1
+
+// String shows address, raw bytes and mnemonic side by side
+func (l Line) String() string {
+	raw := fmt.Sprintf("% X", l.Bytes)
+	if len(raw) < rawWidth {
+		raw += strings.Repeat(" ", rawWidth-len(raw))
+	}
+
+	return fmt.Sprintf("%04X  %s  %s", l.Address, raw, l.Text)
+}
+
+// Disassemble decodes code, which starts at address origin in memory
+func Disassemble(code []byte, origin int) []Line {
+	var lines []Line
+
+	for pos := 0; pos < len(code); {
+		line := decode(code[pos:], origin+pos)
+		lines = append(lines, line)
+		pos += len(line.Bytes)
+	}
+
+	return lines
+}
+
+// DisassembleMemory decodes the memory from address up to (but not including) address to. It reads the memory as it is
+// stored, so watches and devices don't see it and protected memory shows up too.
+func DisassembleMemory(mem *virtualmachine.Memory, from int, to int) ([]Line, error) {
+	if from < 0 || to > mem.Size() || from > to {
+		return nil, fmt.Errorf("illegal range %d..%d", from, to)
+	}
+
+	code, err := mem.Peek(from, to-from)
+	if err != nil {
+		return nil, err
+	}
+
+	return Disassemble(code, from), nil
+}
+
+// Fprint writes the lines to w, one per line
+func Fprint(w io.Writer, lines []Line) error {
+	for _, line := range lines {
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decode turns the first instruction in code into a line
+func decode(code []byte, address int) Line {
+	var in *virtualmachine.Instruction
+	opcode, ok := virtualmachine.DecodeOpcode(code)
+	if ok {
+		in = virtualmachine.LookupOpcode(opcode)
+	}
+	if in == nil || len(code) < in.Size() {
+		return Line{Address: address, Bytes: code[:1], Text: fmt.Sprintf(".byte 0x%02X", code[0]), Data: true}
+	}
+
+	return Line{Address: address, Bytes: code[:in.Size()], Text: in.Format(code[in.Opcode.Size():in.Size()])}
+}
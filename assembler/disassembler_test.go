@@ -0,0 +1,122 @@
+package assembler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+func TestDisassemble(t *testing.T) {
+	source := strings.Join([]string{
+		"push-byte 32",
+		"push-int -325",
+		"push-float 12.5",
+		"get-byte (16)",
+		"put-int {-8}",
+		"add-int",
+		"call (0)",
+		"push-int16 -2",
+		"div-uint32",
+		"get-int32 {-4}",
+		"end",
+	}, "\n")
+
+	program, err := Assemble(source)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	lines := Disassemble(program, 0)
+
+	var text []string
+	for _, line := range lines {
+		text = append(text, line.Text)
+		if line.Data {
+			t.Errorf("unexpected data at %d", line.Address)
+		}
+	}
+	if strings.Join(text, "\n") != source {
+		t.Errorf("expected:\n%s\ngot:\n%s", source, strings.Join(text, "\n"))
+	}
+
+	if lines[1].Address != 2 || !bytes.Equal(lines[1].Bytes, program[2:3+intSize]) {
+		t.Errorf("expected push-int at 2, got %d (% X)", lines[1].Address, lines[1].Bytes)
+	}
+}
+
+func TestDisassembleData(t *testing.T) {
+	// 0x01 is not an opcode, push-int misses part of its operant, 0xFF01 is not an extended opcode, 0xFF misses its second byte
+	lines := Disassemble([]byte{0x01, 0x41, 0x09, 0x05, 0xFF, 0x01, 0xFF}, 0x10)
+
+	expected := []Line{
+		{Address: 0x10, Bytes: []byte{0x01}, Text: ".byte 0x01", Data: true},
+		{Address: 0x11, Bytes: []byte{0x41}, Text: "add-int"},
+		{Address: 0x12, Bytes: []byte{0x09}, Text: ".byte 0x09", Data: true},
+		{Address: 0x13, Bytes: []byte{0x05}, Text: ".byte 0x05", Data: true},
+		{Address: 0x14, Bytes: []byte{0xFF}, Text: ".byte 0xFF", Data: true},
+		{Address: 0x15, Bytes: []byte{0x01}, Text: ".byte 0x01", Data: true},
+		{Address: 0x16, Bytes: []byte{0xFF}, Text: ".byte 0xFF", Data: true},
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i := range expected {
+		if lines[i].String() != expected[i].String() || lines[i].Data != expected[i].Data {
+			t.Errorf("expected %s, got %s", expected[i], lines[i])
+		}
+	}
+}
+
+func TestDisassembleMemory(t *testing.T) {
+	program, err := Assemble("push-byte 7\nnot-byte\nend")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mem := virtualmachine.NewMemory(64)
+	for i, v := range program {
+		err = mem.PutByte(i, v)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	lines, err := DisassembleMemory(mem, 0, len(program))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var out bytes.Buffer
+	err = Fprint(&out, lines)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "0000  08 07                          push-byte 7\n" +
+		"0002  72                             not-byte\n" +
+		"0003  00                             end\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	_, err = DisassembleMemory(mem, 0, 65)
+	if err == nil {
+		t.Errorf("Expected: illegal range")
+	}
+
+	// Neither the watch nor protection notices
+	watched := false
+	mem.SetWatch(func(address int, size int, write bool) { watched = true })
+	err = mem.Protect(0, len(program), virtualmachine.PermExec)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	lines, err = DisassembleMemory(mem, 0, len(program))
+	if err != nil || len(lines) != 3 || watched {
+		t.Errorf("Expected 3 lines without a watch, got %d lines (%v), watched %v", len(lines), err, watched)
+	}
+}
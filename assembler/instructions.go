@@ -0,0 +1,59 @@
+package assembler
+
+import (
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+const intSize = virtualmachine.IntSize
+const floatSize = virtualmachine.FloatSize
+
+// operantForm is how an operant looks in the source, used to pick between instructions sharing a mnemonic
+type operantForm int
+
+const (
+	formNone operantForm = iota
+	formImmediate
+	formAddress
+	formStack
+)
+
+func (form operantForm) String() string {
+	switch form {
+	case formImmediate:
+		return "an immediate operant"
+	case formAddress:
+		return "an (address) operant"
+	case formStack:
+		return "a {stack} operant"
+	}
+
+	return "no operant"
+}
+
+// formOfKind returns the form in the source for the operant of an instruction
+func formOfKind(kind virtualmachine.OperantKind) operantForm {
+	switch kind {
+	case virtualmachine.OperantByte, virtualmachine.OperantInt, virtualmachine.OperantFloat,
+		virtualmachine.OperantInt16, virtualmachine.OperantInt32:
+		return formImmediate
+	case virtualmachine.OperantAddress:
+		return formAddress
+	case virtualmachine.OperantStack:
+		return formStack
+	}
+
+	return formNone
+}
+
+// mnemonics indexes the instruction set on mnemonic and operant form
+var mnemonics = func() map[string]map[operantForm]*virtualmachine.Instruction {
+	index := make(map[string]map[operantForm]*virtualmachine.Instruction)
+	for i := range virtualmachine.InstructionSet {
+		in := &virtualmachine.InstructionSet[i]
+		if index[in.Mnemonic] == nil {
+			index[in.Mnemonic] = make(map[operantForm]*virtualmachine.Instruction)
+		}
+		index[in.Mnemonic][formOfKind(in.Operant)] = in
+	}
+	return index
+}()
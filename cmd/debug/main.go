@@ -0,0 +1,81 @@
+// Command debug loads a program into a virtual machine and debugs it interactively. Files ending in .asm are assembled
+// first, anything else is loaded as is.
+//
+//	debug [-memory n] [-stack n] [-break addr] [-input file] file
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+	"github.com/ralph-nijpels/virtual-machine/assembler"
+	"github.com/ralph-nijpels/virtual-machine/debugger"
+)
+
+func main() {
+	memorySize := flag.Int("memory", 1024, "size of the memory in bytes")
+	stackSize := flag.Int("stack", 256, "size of the stack in bytes")
+	breakpoint := flag.Int("break", -1, "address of an initial breakpoint")
+	input := flag.String("input", "", "file the program reads its input from, standard input is taken by the debugger")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: debug [-memory n] [-stack n] [-break addr] [-input file] file")
+		os.Exit(2)
+	}
+
+	program, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+
+	if strings.HasSuffix(flag.Arg(0), ".asm") {
+		program, err = assembler.Assemble(string(program))
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	vm, err := virtualmachine.NewVirtualMachine(*memorySize, *stackSize)
+	if err != nil {
+		fail(err)
+	}
+
+	vm.SetOutput(os.Stdout)
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+
+		vm.SetInput(file)
+	}
+
+	err = vm.Load(program)
+	if err != nil {
+		fail(err)
+	}
+
+	d := debugger.New(vm)
+	if *breakpoint >= 0 {
+		err = d.SetBreakpoint(*breakpoint)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	err = d.REPL(os.Stdin, os.Stdout)
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,45 @@
+// Command disassemble prints a memory image or program file as addresses, raw bytes and mnemonics.
+//
+//	disassemble [-origin n] [-from n] [-to n] file
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ralph-nijpels/virtual-machine/assembler"
+)
+
+func main() {
+	origin := flag.Int("origin", 0, "address of the first byte of the file")
+	from := flag.Int("from", 0, "offset in the file to start decoding")
+	to := flag.Int("to", -1, "offset in the file to stop decoding, -1 for the end of the file")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: disassemble [-origin n] [-from n] [-to n] file")
+		os.Exit(2)
+	}
+
+	image, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *to < 0 || *to > len(image) {
+		*to = len(image)
+	}
+	if *from < 0 || *from > *to {
+		fmt.Fprintf(os.Stderr, "illegal range %d..%d\n", *from, *to)
+		os.Exit(1)
+	}
+
+	err = assembler.Fprint(os.Stdout, assembler.Disassemble(image[*from:*to], *origin+*from))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,60 @@
+// Command opcodes writes the opcode table of the instruction set, either to stdout or into the readme between the
+// "<!-- begin opcode table -->" and "<!-- end opcode table -->" markers.
+//
+//	opcodes [-readme file]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+const beginMarker = "<!-- begin opcode table -->\n"
+const endMarker = "<!-- end opcode table -->\n"
+
+func main() {
+	readme := flag.String("readme", "", "markdown file to update in place")
+	flag.Parse()
+
+	var table bytes.Buffer
+	err := virtualmachine.WriteInstructionTable(&table)
+	if err != nil {
+		fail(err)
+	}
+
+	if *readme == "" {
+		fmt.Print(table.String())
+		return
+	}
+
+	text, err := ioutil.ReadFile(*readme)
+	if err != nil {
+		fail(err)
+	}
+
+	begin := bytes.Index(text, []byte(beginMarker))
+	end := bytes.Index(text, []byte(endMarker))
+	if begin < 0 || end < begin {
+		fail(fmt.Errorf("%s: opcode table markers missing", *readme))
+	}
+
+	var result bytes.Buffer
+	result.Write(text[:begin+len(beginMarker)])
+	result.Write(table.Bytes())
+	result.Write(text[end:])
+
+	err = ioutil.WriteFile(*readme, result.Bytes(), 0644)
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,70 @@
+package virtualmachine
+
+import "io"
+
+// Config holds everything NewVirtualMachineWithConfig needs to build a virtual machine. Start from DefaultConfig and change
+// what differs, the zero value of some fields (like Extensions) is not the default.
+type Config struct {
+	MemorySize  int
+	StackSize   int
+	Stack       StackPlacement
+	LoadAddress int  // where Load puts the program and execution starts
+	Protection  bool // Load makes the program read-only and the only executable memory
+	HeapDebug   bool // freed heap blocks are quarantined to catch double frees and uses after free
+	GCThreshold int  // bytes allocated on the heap between garbage collections, 0 for no garbage collector
+
+	Tracer   Tracer    // nil for no tracing
+	Input    io.Reader // read by the read-... instructions, nil for no input
+	Output   io.Writer // written by the print-... instructions, nil to throw the output away
+	MaxSteps int       // number of instructions Run and Step execute before ErrBudgetExhausted, 0 for no limit
+
+	FloatDivision   FloatDivision   // what a float division by zero does
+	IntegerOverflow IntegerOverflow // what an int add, sub or mul that doesn't fit does
+
+	Extensions Extension // instruction-set extensions to enable on top of the base set
+}
+
+// StackPlacement decides where in memory the stack lives
+type StackPlacement int
+
+const (
+	StackAtTop    StackPlacement = iota // the last StackSize bytes of memory
+	StackAtBottom                       // the first StackSize bytes of memory, programs have to be loaded above it
+)
+
+// IntegerOverflow decides what an int add, sub or mul does when the result doesn't fit
+type IntegerOverflow int
+
+const (
+	IntegerOverflowWrap IntegerOverflow = iota // wraps around, like Go does
+	IntegerOverflowTrap                        // raises ErrIntegerOverflow
+)
+
+// Extension is a bit set of optional groups of instructions, the base instruction set is always there
+type Extension uint
+
+const (
+	ExtensionBase Extension = 0             // the instructions that are always there
+	ExtensionsAll Extension = ^Extension(0) // every extension
+)
+
+const (
+	ExtensionSizedInts  Extension = 1 << iota // int16 and int32 on the extended page
+	ExtensionBits                             // bit-wise operations, rotates, bit counting and bit tests on ints, 0x7A and 0xFF70
+	ExtensionMath                             // the math library on floats, section 0xA0
+	ExtensionStrings                          // strings, section 0xB0
+	ExtensionHeap                             // allocating, freeing and collecting heap blocks, section 0xC0
+	ExtensionInterrupts                       // ei, di and reti, without them interrupts are never taken
+	ExtensionConsole                          // printing to the output and reading from the input, section 0xD0
+	ExtensionHost                             // syscall, calling the host functions registered with RegisterHostFunc
+)
+
+// DefaultConfig is the configuration NewVirtualMachine uses: stack at the top of memory, programs at address 0, no tracing,
+// no console, no memory protection, no limits, IEEE float division, wrapping ints and every extension enabled
+func DefaultConfig(memorySize int, stackSize int) Config {
+	return Config{
+		MemorySize: memorySize,
+		StackSize:  stackSize,
+		Extensions: ExtensionsAll,
+	}
+}
@@ -0,0 +1,190 @@
+package virtualmachine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConfigStackAtBottom(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Stack = StackAtBottom
+	cfg.LoadAddress = STACK_SIZE
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The program runs above the stack, so absolute addresses include the load address
+	p := NewProgram()
+	p.WriteByte(0x09)                                 // Opcode: push-int
+	p.WriteInt(0x1234)                                // Operant: 0x1234
+	p.WriteByte(0x29)                                 // Opcode: put-int()
+	p.WriteInt(STACK_SIZE + 2*(1+TypeInt.Size()) + 1) // Operant: just after end
+	p.WriteByte(0x00)                                 // Opcode: end
+
+	err = vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.ProgramPointer() != STACK_SIZE {
+		t.Errorf("Expected program pointer %04X, got %04X", STACK_SIZE, vm.ProgramPointer())
+	}
+
+	err = vm.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	value, err := vm.Memory().GetInt(STACK_SIZE + p.Size())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if value != 0x1234 {
+		t.Errorf("Expected 0x1234, got %X", value)
+	}
+	if vm.Stack().Pointer() != 0 {
+		t.Errorf("Expected an empty stack, got %d", vm.Stack().Pointer())
+	}
+
+	// Loading into the stack is not allowed
+	cfg.LoadAddress = 0
+	_, err = NewVirtualMachineWithConfig(cfg)
+	if err == nil {
+		t.Errorf("Expected: illegal load address")
+	}
+}
+
+func TestConfigMaxSteps(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.MaxSteps = 10
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Loops forever
+	p := NewProgram()
+	p.WriteByte(0xE1) // Opcode: jmp()
+	p.WriteInt(0)     // Operant: 0
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("Expected: instruction budget exhausted, got %v", err)
+	}
+	if vm.Steps() != 10 {
+		t.Errorf("Expected 10 steps, got %d", vm.Steps())
+	}
+}
+
+func TestConfigIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 int
+		value2 int
+		trap   bool
+	}{
+		{0x41, math.MaxInt, 1, true},  // add-int
+		{0x41, math.MinInt, -1, true}, // add-int
+		{0x41, math.MaxInt, -1, false},
+		{0x45, math.MinInt, 1, true}, // sub-int
+		{0x45, 0, math.MinInt, true},
+		{0x45, -1, math.MinInt, false},
+		{0x49, math.MaxInt, 2, true}, // mul-int
+		{0x49, math.MinInt, -1, true},
+		{0x49, -1, math.MinInt, true},
+		{0x49, math.MinInt, 1, false},
+	}
+
+	for _, test := range tests {
+		cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+		cfg.IntegerOverflow = IntegerOverflowTrap
+
+		vm, err := NewVirtualMachineWithConfig(cfg)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value1)  // Operant: value1
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value2)  // Operant: value2
+		p.WriteByte(test.opcode) // Opcode: add-int, sub-int or mul-int
+		p.WriteByte(0x00)        // Opcode: end
+
+		err = p.RunOn(vm, nil, nil)
+		if test.trap != errors.Is(err, ErrIntegerOverflow) {
+			t.Errorf("%02X %d %d: expected trap %v, got %v", test.opcode, test.value1, test.value2, test.trap, err)
+		}
+
+		// By default it wraps
+		err = p.Run(nil, nil)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+	}
+}
+
+func TestConfigExtensions(t *testing.T) {
+	tests := []struct {
+		extension Extension
+		opcode    Opcode
+	}{
+		{ExtensionSizedInts, 0xFF40},
+		{ExtensionBits, 0xFF70},
+		{ExtensionBits, 0x7C},
+		{ExtensionMath, 0xA0},
+		{ExtensionStrings, 0xB1},
+		{ExtensionHeap, 0xC1},
+		{ExtensionInterrupts, 0xFC},
+		{ExtensionConsole, 0xD0},
+		{ExtensionHost, 0xFA},
+	}
+
+	for _, test := range tests {
+		cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+		cfg.Extensions = ExtensionsAll &^ test.extension
+
+		vm, err := NewVirtualMachineWithConfig(cfg)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		p := NewProgram()
+		p.WriteOpcode(test.opcode) // Opcode: from the extension
+		p.WriteByte(0x00)          // Opcode: end
+
+		err = p.RunOn(vm, nil, nil)
+		if !errors.Is(err, ErrUnknownOpcode) {
+			t.Errorf("%04X: expected unknown opcode without its extension, got %v", test.opcode, err)
+		}
+	}
+}
+
+func TestConfigTracer(t *testing.T) {
+	count := 0
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Tracer = countingTracer(func(event TraceEvent) { count++ })
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = tracedProgram().RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 events, got %d", count)
+	}
+}
+
+type countingTracer func(event TraceEvent)
+
+func (c countingTracer) Trace(event TraceEvent) {
+	c(event)
+}
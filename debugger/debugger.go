@@ -0,0 +1,373 @@
+// Package debugger runs a virtual machine instruction by instruction, stopping at breakpoints and watchpoints.
+package debugger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+)
+
+// opcodes the debugger needs to recognise for step-over and step-out, taken from the instruction set
+var (
+	opcodeRet         = lookup("ret", virtualmachine.OperantNone)
+	opcodeReti        = lookup("reti", virtualmachine.OperantNone)
+	opcodeCall        = lookup("call", virtualmachine.OperantNone)
+	opcodeCallAddress = lookup("call", virtualmachine.OperantAddress)
+)
+
+// Access selects which memory accesses trigger a watchpoint
+type Access int
+
+const (
+	Read Access = 1 << iota
+	Write
+)
+
+func (a Access) String() string {
+	switch a {
+	case Read:
+		return "read"
+	case Write:
+		return "write"
+	case Read | Write:
+		return "read/write"
+	}
+
+	return "none"
+}
+
+// Reason tells why the debugger stopped
+type Reason int
+
+const (
+	Stepped    Reason = iota // the requested step(s) are done
+	Breakpoint               // the program pointer reached a breakpoint
+	Watchpoint               // an instruction accessed a watched address
+	Ended                    // the program executed end
+	Faulted                  // the program ran into an error
+)
+
+// Stop describes where and why the debugger stopped
+type Stop struct {
+	Reason         Reason
+	ProgramPointer int
+	Address        int    // watched address that was accessed
+	Access         Access // how the watched address was accessed
+	Err            error  // the fault, for Faulted
+}
+
+func (s Stop) String() string {
+	switch s.Reason {
+	case Breakpoint:
+		return fmt.Sprintf("breakpoint at %04X", s.ProgramPointer)
+	case Watchpoint:
+		return fmt.Sprintf("watchpoint %04X (%s), now at %04X", s.Address, s.Access, s.ProgramPointer)
+	case Ended:
+		return fmt.Sprintf("program ended at %04X", s.ProgramPointer)
+	case Faulted:
+		return fmt.Sprintf("fault: %s", s.Err)
+	}
+
+	return fmt.Sprintf("at %04X", s.ProgramPointer)
+}
+
+// Watch is a watchpoint: a watched address and the accesses that trigger it
+type Watch struct {
+	Address int
+	Access  Access
+}
+
+// Debugger controls a virtual machine
+type Debugger struct {
+	vm          *virtualmachine.VirtualMachine
+	breakpoints map[int]bool
+	watchpoints map[int]Access
+
+	stepping bool  // only accesses made while executing an instruction count
+	hit      *Stop // first watchpoint hit during the current instruction
+}
+
+// -- Breakpoints and watchpoints -----------------------------------------------------------------------------------------------
+
+// SetBreakpoint stops execution before the instruction at address is executed
+func (d *Debugger) SetBreakpoint(address int) error {
+	if address < 0 || address >= d.vm.Memory().Size() {
+		return fmt.Errorf("illegal address")
+	}
+
+	d.breakpoints[address] = true
+	return nil
+}
+
+// ClearBreakpoint removes the breakpoint at address
+func (d *Debugger) ClearBreakpoint(address int) {
+	delete(d.breakpoints, address)
+}
+
+// Breakpoints lists the breakpoints in order
+func (d *Debugger) Breakpoints() []int {
+	var result []int
+	for address := range d.breakpoints {
+		result = append(result, address)
+	}
+	sort.Ints(result)
+
+	return result
+}
+
+// SetWatchpoint stops execution after an instruction that accesses address
+func (d *Debugger) SetWatchpoint(address int, access Access) error {
+	if address < 0 || address >= d.vm.Memory().Size() {
+		return fmt.Errorf("illegal address")
+	}
+	if access&(Read|Write) == 0 {
+		return fmt.Errorf("illegal access")
+	}
+
+	d.watchpoints[address] = access
+	return nil
+}
+
+// ClearWatchpoint removes the watchpoint at address
+func (d *Debugger) ClearWatchpoint(address int) {
+	delete(d.watchpoints, address)
+}
+
+// Watchpoints lists the watchpoints in order of address
+func (d *Debugger) Watchpoints() []Watch {
+	var result []Watch
+	for address, access := range d.watchpoints {
+		result = append(result, Watch{Address: address, Access: access})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Address < result[j].Address })
+
+	return result
+}
+
+// watch is installed on the memory of the virtual machine
+func (d *Debugger) watch(address int, size int, write bool) {
+	if !d.stepping || d.hit != nil {
+		return
+	}
+
+	// Fetching the instruction and its operant is not an access by the program
+	pc := d.vm.ProgramPointer()
+	if _, in := d.instructionAt(pc); !write && in != nil && address >= pc && address+size <= pc+in.Size() {
+		return
+	}
+
+	access := Read
+	if write {
+		access = Write
+	}
+
+	for i := address; i < address+size; i++ {
+		if d.watchpoints[i]&access != 0 {
+			d.hit = &Stop{Reason: Watchpoint, Address: i, Access: access}
+			return
+		}
+	}
+}
+
+// -- Execution -----------------------------------------------------------------------------------------------------------------
+
+// step executes a single instruction, it tells whether to stop
+func (d *Debugger) step() (Stop, bool) {
+	d.hit = nil
+	d.stepping = true
+	atEnd, err := d.vm.Step()
+	d.stepping = false
+
+	stop := Stop{Reason: Stepped, ProgramPointer: d.vm.ProgramPointer()}
+	switch {
+	case err != nil:
+		stop.Reason = Faulted
+		stop.Err = err
+		return stop, true
+	case atEnd:
+		stop.Reason = Ended
+		return stop, true
+	case d.hit != nil:
+		d.hit.ProgramPointer = stop.ProgramPointer
+		return *d.hit, true
+	case d.breakpoints[stop.ProgramPointer]:
+		stop.Reason = Breakpoint
+		return stop, true
+	}
+
+	return stop, false
+}
+
+// instructionAt returns the opcode at address and its instruction, nil when it is unknown. It reads memory as it is
+// stored, so watchpoints and devices don't notice.
+func (d *Debugger) instructionAt(address int) (virtualmachine.Opcode, *virtualmachine.Instruction) {
+	code, err := d.vm.Memory().Peek(address, 2)
+	if err != nil {
+		code, err = d.vm.Memory().Peek(address, 1)
+	}
+	if err != nil {
+		return 0, nil
+	}
+
+	opcode, ok := virtualmachine.DecodeOpcode(code)
+	if !ok {
+		return 0, nil
+	}
+
+	return opcode, virtualmachine.LookupOpcode(opcode)
+}
+
+// next returns the address of the instruction the next step executes, and whether it takes an interrupt first
+func (d *Debugger) next() (address int, interrupt bool) {
+	address = d.vm.ProgramPointer()
+	pending := d.vm.PendingInterrupts()
+	if !d.vm.InterruptsEnabled() || pending == 0 {
+		return address, false
+	}
+
+	// The lowest pending interrupt goes first, without a handler it is thrown away
+	n := 0
+	for pending&(1<<uint(n)) == 0 {
+		n++
+	}
+	vectors, count := d.vm.InterruptVectors()
+	if n >= count {
+		return address, false
+	}
+	vector, err := d.vm.Memory().Peek(vectors+n*virtualmachine.IntSize, virtualmachine.IntSize)
+	if err != nil {
+		return address, false
+	}
+	handler := int(int64(binary.LittleEndian.Uint64(vector)))
+	if handler == 0 {
+		return address, false
+	}
+
+	return handler, true
+}
+
+// enteredFaultHandler tells if the instruction at address, of size bytes, jumped to the fault handler: the program pointer
+// is at the handler with the fault code on top of the address of the next instruction
+func (d *Debugger) enteredFaultHandler(address int, size int) bool {
+	if d.vm.FaultHandler() < 0 || d.vm.ProgramPointer() != d.vm.FaultHandler() {
+		return false
+	}
+
+	next, err := d.vm.Stack().GetInt(-2 * virtualmachine.IntSize)
+	return err == nil && next == address+size
+}
+
+// Step executes a single instruction
+func (d *Debugger) Step() Stop {
+	stop, _ := d.step()
+	return stop
+}
+
+// Continue executes until a breakpoint, a watchpoint, the end of the program or a fault
+func (d *Debugger) Continue() Stop {
+	for {
+		stop, done := d.step()
+		if done {
+			return stop
+		}
+	}
+}
+
+// StepOver executes a single instruction, but runs a call up to the moment it returns. An interrupt taken first runs up to
+// the moment it returns too, the instruction is executed after that.
+func (d *Debugger) StepOver() Stop {
+	for {
+		_, interrupt := d.next()
+		if !interrupt {
+			break
+		}
+
+		stop, done := d.until(d.vm.ProgramPointer(), d.vm.Stack().Pointer())
+		if done {
+			return stop
+		}
+	}
+
+	address := d.vm.ProgramPointer()
+	opcode, in := d.instructionAt(address)
+	if opcode != opcodeCall && opcode != opcodeCallAddress {
+		return d.Step()
+	}
+
+	stop, _ := d.until(address+in.Size(), d.vm.Stack().Pointer())
+	return stop
+}
+
+// until executes until the program pointer is at address with the stack no deeper than depth, recursive calls return
+// deeper in the stack. It tells whether it stopped somewhere else.
+func (d *Debugger) until(address int, depth int) (Stop, bool) {
+	for {
+		stop, done := d.step()
+		if stop.ProgramPointer == address && d.vm.Stack().Pointer() <= depth &&
+			(stop.Reason == Stepped || stop.Reason == Breakpoint) {
+			return stop, false
+		}
+		if done {
+			return stop, true
+		}
+	}
+}
+
+// StepOut executes until the current subroutine, interrupt handler or fault handler returns. Calls, interrupts and faults
+// jumping to the fault handler go a level deeper, ret and reti come back up.
+func (d *Debugger) StepOut() Stop {
+	depth := 0
+
+	for {
+		address, interrupt := d.next()
+		if interrupt {
+			depth++
+		}
+
+		opcode, in := d.instructionAt(address)
+		switch opcode {
+		case opcodeCall, opcodeCallAddress:
+			depth++
+		case opcodeRet, opcodeReti:
+			depth--
+		}
+
+		stop, done := d.step()
+		if done || depth < 0 {
+			return stop
+		}
+		if in != nil && d.enteredFaultHandler(address, in.Size()) {
+			depth++
+		}
+	}
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// lookup finds the opcode of an instruction in the instruction set
+func lookup(mnemonic string, operant virtualmachine.OperantKind) virtualmachine.Opcode {
+	for i := range virtualmachine.InstructionSet {
+		in := &virtualmachine.InstructionSet[i]
+		if in.Mnemonic == mnemonic && in.Operant == operant {
+			return in.Opcode
+		}
+	}
+
+	panic(fmt.Sprintf("debugger: no instruction %s", mnemonic))
+}
+
+// -- Companion functions -------------------------------------------------------------------------------------------------------
+
+// New attaches a debugger to a virtual machine with a program loaded
+func New(vm *virtualmachine.VirtualMachine) *Debugger {
+	d := &Debugger{
+		vm:          vm,
+		breakpoints: make(map[int]bool),
+		watchpoints: make(map[int]Access),
+	}
+	vm.Memory().SetWatch(d.watch)
+
+	return d
+}
@@ -0,0 +1,280 @@
+package debugger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+	"github.com/ralph-nijpels/virtual-machine/assembler"
+)
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// Squares 5 in a subroutine and stores the result
+const testSource = `
+start:  push-int 5
+        call (square)
+back:   put-int (result)
+        end
+square: get-int {-16}
+        get-int {-24}
+        mul-int
+        put-int {-16}
+        ret
+result: .int 0`
+
+// addresses of the labels in testSource
+var (
+	n      = 1 + virtualmachine.TypeInt.Size() // instruction with an int operant
+	call   = n
+	back   = 2 * n
+	end    = 3 * n
+	square = 3*n + 1
+	ret    = 6*n + 2
+	result = 6*n + 3
+)
+
+func newTestDebugger(t *testing.T, source string) *Debugger {
+	program, err := assembler.Assemble(source)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vm, err := virtualmachine.NewVirtualMachine(256, 64)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = vm.Load(program)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return New(vm)
+}
+
+func expectStop(t *testing.T, stop Stop, reason Reason, programPointer int) {
+	t.Helper()
+	if stop.Reason != reason || stop.ProgramPointer != programPointer {
+		t.Errorf("Expected reason %d at %04X, got %s", reason, programPointer, stop)
+	}
+}
+
+// -- Tests ---------------------------------------------------------------------------------------------------------------------
+
+func TestDebuggerStep(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	expectStop(t, d.Step(), Stepped, call)
+	expectStop(t, d.Step(), Stepped, square)
+}
+
+func TestDebuggerBreakpoint(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	err := d.SetBreakpoint(ret)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectStop(t, d.Continue(), Breakpoint, ret)
+	expectStop(t, d.Continue(), Ended, end)
+
+	d.ClearBreakpoint(ret)
+	if len(d.Breakpoints()) != 0 {
+		t.Errorf("Expected no breakpoints")
+	}
+
+	err = d.SetBreakpoint(-1)
+	if err == nil {
+		t.Errorf("Expected: illegal address")
+	}
+}
+
+func TestDebuggerWatchpoint(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	// Only put-int (result) writes the result, get-int reads below it on the stack
+	err := d.SetWatchpoint(result, Write)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stop := d.Continue()
+	expectStop(t, stop, Watchpoint, end)
+	if stop.Address != result || stop.Access != Write {
+		t.Errorf("Expected write of %04X, got %s", result, stop)
+	}
+
+	// Reads of the argument through the stack-pointer
+	d = newTestDebugger(t, testSource)
+	stackBase := d.vm.Memory().Size() - 64
+
+	err = d.SetWatchpoint(stackBase, Read)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectStop(t, d.Continue(), Watchpoint, square+n)
+}
+
+func TestDebuggerStepOver(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	expectStop(t, d.StepOver(), Stepped, call)
+	expectStop(t, d.StepOver(), Stepped, back)
+	expectStop(t, d.StepOver(), Stepped, end)
+}
+
+func TestDebuggerStepOut(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	d.Step()
+	d.Step()
+	expectStop(t, d.StepOut(), Stepped, back)
+}
+
+func TestDebuggerFault(t *testing.T) {
+	d := newTestDebugger(t, ".byte 0x08, 0x01, 0x01")
+
+	stop := d.Continue()
+	expectStop(t, stop, Faulted, 2)
+	if !errors.Is(stop.Err, virtualmachine.ErrUnknownOpcode) {
+		t.Errorf("Expected: unknown opcode, got %v", stop.Err)
+	}
+}
+
+func TestDebuggerREPL(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	var out bytes.Buffer
+	in := strings.NewReader("break 0x1C\nwatch 0x39 w\ninfo\ncontinue\nbogus\nquit\nstep\n")
+
+	err := d.REPL(in, &out)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, expected := range []string{
+		"breakpoint 001C\n",
+		"watchpoint 0039 (write)\n",
+		"breakpoint at 001C\n",
+		"get-int {-16}\n",
+		"error: unknown command \"bogus\", try help\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, out.String())
+		}
+	}
+
+	// The state of the virtual machine goes to out as well
+	for _, expected := range []string{"Stack", "Memory"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, out.String())
+		}
+	}
+
+	// quit stops reading commands
+	if d.vm.ProgramPointer() != square {
+		t.Errorf("Expected to stay at %04X, got %04X", square, d.vm.ProgramPointer())
+	}
+}
+
+func TestDebuggerWatchpoints(t *testing.T) {
+	d := newTestDebugger(t, testSource)
+
+	for _, address := range []int{result, back, call} {
+		err := d.SetWatchpoint(address, Read)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	watchpoints := d.Watchpoints()
+	if len(watchpoints) != 3 || watchpoints[0].Address != call || watchpoints[1].Address != back ||
+		watchpoints[2] != (Watch{Address: result, Access: Read}) {
+		t.Errorf("Expected watchpoints in order of address, got %v", watchpoints)
+	}
+
+	// Executing the watched instructions doesn't read them, nothing reads the result
+	expectStop(t, d.Continue(), Ended, end)
+}
+
+func TestDebuggerStepOutInterrupt(t *testing.T) {
+	d := newTestDebugger(t, `
+start:   ei
+         push-int 1
+         pop-int
+         end
+handler: push-int 7
+         pop-int
+         reti
+vectors: .int handler`)
+
+	err := d.vm.SetInterruptVectors(2*n+5, 1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectStop(t, d.Step(), Stepped, 1)
+	err = d.vm.RaiseInterrupt(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Taking the interrupt executes the first instruction of the handler, reti returns to push-int
+	expectStop(t, d.Step(), Stepped, 2*n+3)
+	expectStop(t, d.StepOut(), Stepped, 1)
+}
+
+func TestDebuggerStepOverInterrupt(t *testing.T) {
+	d := newTestDebugger(t, `
+start:   ei
+         push-int 1
+         pop-int
+         end
+handler: push-int 7
+         pop-int
+         reti
+vectors: .int handler`)
+
+	err := d.vm.SetInterruptVectors(2*n+5, 1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectStop(t, d.Step(), Stepped, 1)
+	err = d.vm.RaiseInterrupt(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The handler runs up to reti, then push-int is executed
+	expectStop(t, d.StepOver(), Stepped, 1+n)
+	if d.vm.PendingInterrupts() != 0 || d.vm.Stack().Pointer() != virtualmachine.IntSize {
+		t.Errorf("Expected the interrupt taken and 1 on the stack, got pending %b and sp %d", d.vm.PendingInterrupts(), d.vm.Stack().Pointer())
+	}
+}
+
+func TestDebuggerStepOutFaultHandler(t *testing.T) {
+	d := newTestDebugger(t, `
+start:   call (divide)
+         end
+divide:  push-int 1
+         push-int 0
+         div-int
+         ret
+handler: pop-int
+         ret`)
+
+	err := d.vm.SetFaultHandler(3*n + 3)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The fault handler returns into divide, which returns after the call
+	expectStop(t, d.Step(), Stepped, n+1)
+	expectStop(t, d.StepOut(), Stepped, n)
+}
@@ -0,0 +1,223 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	virtualmachine "github.com/ralph-nijpels/virtual-machine"
+	"github.com/ralph-nijpels/virtual-machine/assembler"
+)
+
+const help = `commands:
+  s, step [n]            execute n (default 1) instructions
+  n, next                execute an instruction, running calls up to their return
+  f, finish              execute until the current subroutine returns
+  c, continue            execute until a breakpoint, watchpoint, end or fault
+  b, break <addr>        set a breakpoint
+  d, delete <addr>       remove a breakpoint
+  w, watch <addr> [r|w]  stop on reads and/or writes of an address (default both)
+  unwatch <addr>         remove a watchpoint
+  i, info                list breakpoints and watchpoints
+  l, list [addr [n]]     disassemble n (default 8) instructions from addr (default the program pointer)
+  stack, memory          show the stack or the memory
+  q, quit                leave the debugger`
+
+// REPL reads commands from in until quit or the end of the input, answers go to out. The state of the virtual machine
+// is shown with its stack and memory renderers after each stop.
+func (d *Debugger) REPL(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+
+	d.showLocation(out)
+	fmt.Fprint(out, "> ")
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			if fields[0] == "q" || fields[0] == "quit" {
+				return nil
+			}
+
+			err := d.command(fields[0], fields[1:], out)
+			if err != nil {
+				fmt.Fprintln(out, "error:", err)
+			}
+		}
+		fmt.Fprint(out, "> ")
+	}
+
+	return scanner.Err()
+}
+
+// command executes a single REPL command
+func (d *Debugger) command(name string, args []string, out io.Writer) error {
+	switch name {
+	case "s", "step":
+		count, err := optionalNumber(args, 0, 1)
+		if err != nil {
+			return err
+		}
+		stop := Stop{ProgramPointer: d.vm.ProgramPointer()}
+		for i := 0; i < count; i++ {
+			var done bool
+			stop, done = d.step()
+			if done {
+				break
+			}
+		}
+		d.showStop(stop, out)
+
+	case "n", "next":
+		d.showStop(d.StepOver(), out)
+
+	case "f", "finish":
+		d.showStop(d.StepOut(), out)
+
+	case "c", "continue":
+		d.showStop(d.Continue(), out)
+
+	case "b", "break":
+		address, err := requiredNumber(args, 0)
+		if err != nil {
+			return err
+		}
+		return d.SetBreakpoint(address)
+
+	case "d", "delete":
+		address, err := requiredNumber(args, 0)
+		if err != nil {
+			return err
+		}
+		d.ClearBreakpoint(address)
+
+	case "w", "watch":
+		address, err := requiredNumber(args, 0)
+		if err != nil {
+			return err
+		}
+		access := Read | Write
+		if len(args) > 1 {
+			switch args[1] {
+			case "r":
+				access = Read
+			case "w":
+				access = Write
+			case "rw":
+			default:
+				return fmt.Errorf("access should be r, w or rw")
+			}
+		}
+		return d.SetWatchpoint(address, access)
+
+	case "unwatch":
+		address, err := requiredNumber(args, 0)
+		if err != nil {
+			return err
+		}
+		d.ClearWatchpoint(address)
+
+	case "i", "info":
+		for _, address := range d.Breakpoints() {
+			fmt.Fprintf(out, "breakpoint %04X\n", address)
+		}
+		for _, watchpoint := range d.Watchpoints() {
+			fmt.Fprintf(out, "watchpoint %04X (%s)\n", watchpoint.Address, watchpoint.Access)
+		}
+
+	case "l", "list":
+		address, err := optionalNumber(args, 0, d.vm.ProgramPointer())
+		if err != nil {
+			return err
+		}
+		count, err := optionalNumber(args, 1, 8)
+		if err != nil {
+			return err
+		}
+		return d.list(address, count, out)
+
+	case "stack":
+		d.vm.ShowStackTo(out)
+
+	case "memory":
+		d.vm.ShowMemoryTo(out)
+
+	case "h", "help":
+		fmt.Fprintln(out, help)
+
+	default:
+		return fmt.Errorf("unknown command %q, try help", name)
+	}
+
+	return nil
+}
+
+// showStop reports why the debugger stopped and shows the state of the virtual machine
+func (d *Debugger) showStop(stop Stop, out io.Writer) {
+	fmt.Fprintln(out, stop)
+	d.showLocation(out)
+	d.vm.ShowStackTo(out)
+	d.vm.ShowMemoryTo(out)
+}
+
+// showLocation disassembles the instruction at the program pointer
+func (d *Debugger) showLocation(out io.Writer) {
+	d.list(d.vm.ProgramPointer(), 1, out)
+}
+
+// list disassembles count instructions from address
+func (d *Debugger) list(address int, count int, out io.Writer) error {
+	to := address + count*d.maxInstructionSize()
+	if to > d.vm.Memory().Size() {
+		to = d.vm.Memory().Size()
+	}
+
+	lines, err := assembler.DisassembleMemory(d.vm.Memory(), address, to)
+	if err != nil {
+		return err
+	}
+	if len(lines) > count {
+		lines = lines[:count]
+	}
+
+	return assembler.Fprint(out, lines)
+}
+
+// maxInstructionSize is the size of the longest instruction in the instruction set
+func (d *Debugger) maxInstructionSize() int {
+	size := 1
+	for i := range virtualmachine.InstructionSet {
+		if virtualmachine.InstructionSet[i].Size() > size {
+			size = virtualmachine.InstructionSet[i].Size()
+		}
+	}
+
+	return size
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+func requiredNumber(args []string, index int) (int, error) {
+	if index >= len(args) {
+		return 0, fmt.Errorf("missing address")
+	}
+
+	return parseNumber(args[index])
+}
+
+func optionalNumber(args []string, index int, value int) (int, error) {
+	if index >= len(args) {
+		return value, nil
+	}
+
+	return parseNumber(args[index])
+}
+
+func parseNumber(text string) (int, error) {
+	value, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("illegal number %q", text)
+	}
+
+	return int(value), nil
+}
@@ -0,0 +1,270 @@
+package virtualmachine
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Device is a peripheral mapped into memory. Reads and writes of bytes, ints and floats in its address range go to the
+// device instead of memory, with the offset from the start of the range. Returning an error faults the instruction with
+// ErrDevice, or with the kind of a VMError.
+type Device interface {
+	GetByte(offset int) (byte, error)
+	PutByte(offset int, value byte) error
+	GetInt(offset int) (int, error)
+	PutInt(offset int, value int) error
+	GetFloat(offset int) (float64, error)
+	PutFloat(offset int, value float64) error
+}
+
+// mapping is a device attached to an address range, a restored snapshot has no device yet until it is attached again
+type mapping struct {
+	start  int
+	size   int
+	device Device
+}
+
+var errDeviceType = errors.New("devices only take bytes, ints and floats")
+var errDeviceEdge = errors.New("access crosses the edge of a device")
+var errDeviceMissing = errors.New("device of the snapshot is not attached again")
+
+// newDeviceError creates a device fault at address
+func newDeviceError(address int, cause error) *VMError {
+	err := newVMError(ErrDevice, address)
+	err.Cause = cause
+	return err
+}
+
+// -- Attaching devices ---------------------------------------------------------------------------------------------------------
+
+// AttachDevice maps device over size bytes from start. The range has to be inside memory and can't overlap another device.
+// Everything goes through the device, the stack and the heap included, so keep it out of their way. On a restored virtual
+// machine it also attaches a device again, over the range it had when the snapshot was taken.
+func (mem *Memory) AttachDevice(start int, size int, device Device) error {
+	if device == nil || size <= 0 || start < 0 || start+size > len(mem.memory) {
+		return newVMError(ErrIllegalAddress, start)
+	}
+	for i, m := range mem.devices {
+		if m.start == start && m.size == size && m.device == nil {
+			mem.devices[i].device = device
+			return nil
+		}
+	}
+	if mem.mapped(start, size) {
+		return newDeviceError(start, errors.New("overlaps another device"))
+	}
+
+	i := sort.Search(len(mem.devices), func(i int) bool { return mem.devices[i].start > start })
+	mem.devices = append(mem.devices, mapping{})
+	copy(mem.devices[i+1:], mem.devices[i:])
+	mem.devices[i] = mapping{start: start, size: size, device: device}
+
+	return nil
+}
+
+// DetachDevice removes the device attached at start, the memory below it shows again
+func (mem *Memory) DetachDevice(start int) error {
+	for i, m := range mem.devices {
+		if m.start == start {
+			mem.devices = append(mem.devices[:i], mem.devices[i+1:]...)
+			return nil
+		}
+	}
+
+	return newVMError(ErrIllegalAddress, start)
+}
+
+// device finds the device a read or write of size bytes at address goes to, nil for plain memory
+func (mem *Memory) device(address int, size int) (Device, int, error) {
+	for _, m := range mem.devices {
+		if address+size <= m.start {
+			break
+		}
+		if address >= m.start+m.size {
+			continue
+		}
+		if address < m.start || address+size > m.start+m.size {
+			return nil, 0, newDeviceError(address, errDeviceEdge)
+		}
+		if m.device == nil {
+			return nil, 0, newDeviceError(address, errDeviceMissing)
+		}
+
+		return m.device, address - m.start, nil
+	}
+
+	return nil, 0, nil
+}
+
+// mapped tells if any of size bytes from address belong to a device
+func (mem *Memory) mapped(address int, size int) bool {
+	for _, m := range mem.devices {
+		if address < m.start+m.size && m.start < address+size {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deviceError turns an error of a device into a fault at address
+func (mem *Memory) deviceError(err error, address int) error {
+	if err == nil {
+		return nil
+	}
+
+	var vmErr *VMError
+	if errors.As(err, &vmErr) {
+		if vmErr.Address == -1 {
+			vmErr.Address = address
+		}
+		return vmErr
+	}
+
+	return newDeviceError(address, err)
+}
+
+// -- Console device ------------------------------------------------------------------------------------------------------------
+
+// ConsoleDeviceSize is the number of bytes a console device takes
+const ConsoleDeviceSize = 2
+
+// ConsoleDevice is a character console. Writing a byte at offset 0 writes it to the output, reading it reads a byte from the
+// input, 0 at the end. The byte at offset 1 reads 1 once the input ended.
+type ConsoleDevice struct {
+	input  *bufio.Reader
+	output io.Writer
+	ended  bool
+}
+
+// NewConsoleDevice creates a console reading from r and writing to w, either can be nil
+func NewConsoleDevice(r io.Reader, w io.Writer) *ConsoleDevice {
+	console := &ConsoleDevice{output: w}
+	if r != nil {
+		console.input = bufio.NewReader(r)
+	} else {
+		console.ended = true
+	}
+
+	return console
+}
+
+func (c *ConsoleDevice) GetByte(offset int) (byte, error) {
+	if offset == 1 {
+		if c.ended {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	if c.ended {
+		return 0, nil
+	}
+	value, err := c.input.ReadByte()
+	if err == io.EOF {
+		c.ended = true
+		return 0, nil
+	}
+	if err != nil {
+		return 0, ioError(err)
+	}
+
+	return value, nil
+}
+
+func (c *ConsoleDevice) PutByte(offset int, value byte) error {
+	if offset != 0 {
+		return errors.New("the status of the console is read-only")
+	}
+	if c.output == nil {
+		return nil
+	}
+
+	_, err := c.output.Write([]byte{value})
+	if err != nil {
+		return ioError(err)
+	}
+
+	return nil
+}
+
+func (c *ConsoleDevice) GetInt(offset int) (int, error)           { return 0, errDeviceType }
+func (c *ConsoleDevice) PutInt(offset int, value int) error       { return errDeviceType }
+func (c *ConsoleDevice) GetFloat(offset int) (float64, error)     { return 0, errDeviceType }
+func (c *ConsoleDevice) PutFloat(offset int, value float64) error { return errDeviceType }
+
+// -- Timer device --------------------------------------------------------------------------------------------------------------
+
+// TimerDeviceSize is the number of bytes a timer device takes
+const TimerDeviceSize = IntSize
+
+// TimerDevice counts milliseconds. Reading the int at offset 0 gives the milliseconds since the timer was created, writing
+// it sets the count.
+type TimerDevice struct {
+	now   func() time.Time
+	start time.Time
+}
+
+// NewTimerDevice creates a timer that starts counting at 0
+func NewTimerDevice() *TimerDevice {
+	return newTimerDevice(time.Now)
+}
+
+// newTimerDevice creates a timer on a clock of choice
+func newTimerDevice(now func() time.Time) *TimerDevice {
+	return &TimerDevice{now: now, start: now()}
+}
+
+func (d *TimerDevice) GetInt(offset int) (int, error) {
+	return int(d.now().Sub(d.start).Milliseconds()), nil
+}
+
+func (d *TimerDevice) PutInt(offset int, value int) error {
+	d.start = d.now().Add(-time.Duration(value) * time.Millisecond)
+	return nil
+}
+
+func (d *TimerDevice) GetByte(offset int) (byte, error)         { return 0, errDeviceType }
+func (d *TimerDevice) PutByte(offset int, value byte) error     { return errDeviceType }
+func (d *TimerDevice) GetFloat(offset int) (float64, error)     { return 0, errDeviceType }
+func (d *TimerDevice) PutFloat(offset int, value float64) error { return errDeviceType }
+
+// -- Random device -------------------------------------------------------------------------------------------------------------
+
+// RandomDeviceSize is the number of bytes a random device takes
+const RandomDeviceSize = IntSize
+
+// RandomDevice generates pseudo-random numbers at offset 0: a byte, a non-negative int or a float in [0, 1) depending on
+// what is read. Writing an int seeds it again, so a program can repeat a sequence.
+type RandomDevice struct {
+	random *rand.Rand
+}
+
+// NewRandomDevice creates a random number generator starting from seed
+func NewRandomDevice(seed int64) *RandomDevice {
+	return &RandomDevice{random: rand.New(rand.NewSource(seed))}
+}
+
+func (d *RandomDevice) GetByte(offset int) (byte, error) {
+	return byte(d.random.Intn(256)), nil
+}
+
+func (d *RandomDevice) GetInt(offset int) (int, error) {
+	return d.random.Int(), nil
+}
+
+func (d *RandomDevice) GetFloat(offset int) (float64, error) {
+	return d.random.Float64(), nil
+}
+
+func (d *RandomDevice) PutInt(offset int, value int) error {
+	d.random.Seed(int64(value))
+	return nil
+}
+
+func (d *RandomDevice) PutByte(offset int, value byte) error     { return errDeviceType }
+func (d *RandomDevice) PutFloat(offset int, value float64) error { return errDeviceType }
@@ -0,0 +1,171 @@
+package virtualmachine
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConsole = 160
+const testTimer = 168
+const testRandom = 176
+
+// deviceMachine creates a virtual machine with a console, a timer and a random number generator below the stack
+func deviceMachine(t *testing.T, input string, output *bytes.Buffer, now func() time.Time) *VirtualMachine {
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mem := vm.Memory()
+	err = mem.AttachDevice(testConsole, ConsoleDeviceSize, NewConsoleDevice(strings.NewReader(input), output))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = mem.AttachDevice(testTimer, TimerDeviceSize, newTimerDevice(now))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = mem.AttachDevice(testRandom, RandomDeviceSize, NewRandomDevice(1))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return vm
+}
+
+func TestConsoleDevice(t *testing.T) {
+	// Echoes the input in upper case until it ends
+	p := NewProgram()
+	p.WriteByte(0x20)           // Opcode: get-byte()
+	p.WriteInt(testConsole)     // Operant: console data
+	p.WriteByte(0x20)           // Opcode: get-byte()
+	p.WriteInt(testConsole + 1) // Operant: console status
+	p.WriteByte(0xF0)           // Opcode: jmpnz-byte()
+	p.WriteInt(48)              // Operant: end
+	p.WriteByte(0x08)           // Opcode: push-byte
+	p.WriteByte(0x20)           // Operant: 0x20
+	p.WriteByte(0x44)           // Opcode: sub-byte
+	p.WriteByte(0x28)           // Opcode: put-byte()
+	p.WriteInt(testConsole)     // Operant: console data
+	p.WriteByte(0xE1)           // Opcode: jmp()
+	p.WriteInt(0)               // Operant: 0
+	p.WriteByte(0x0C)           // Opcode: pop-byte
+	p.WriteByte(0x00)           // Opcode: end
+
+	var output bytes.Buffer
+	vm := deviceMachine(t, "hello", &output, time.Now)
+
+	err := p.RunOn(vm, NewBuffer(), nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if output.String() != "HELLO" {
+		t.Errorf("Expected \"HELLO\", got %q", output.String())
+	}
+
+	// Memory below the console is untouched
+	value, err := vm.Memory().GetByte(testConsole - 1)
+	if err != nil || value != 0 {
+		t.Errorf("Expected 0, got %d (%v)", value, err)
+	}
+}
+
+func TestTimerDevice(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	now := func() time.Time { return clock }
+
+	vm := deviceMachine(t, "", nil, now)
+	mem := vm.Memory()
+
+	clock = clock.Add(1500 * time.Millisecond)
+	value, err := mem.GetInt(testTimer)
+	if err != nil || value != 1500 {
+		t.Errorf("Expected 1500, got %d (%v)", value, err)
+	}
+
+	err = mem.PutInt(testTimer, 10)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	clock = clock.Add(5 * time.Millisecond)
+	value, err = mem.GetInt(testTimer)
+	if err != nil || value != 15 {
+		t.Errorf("Expected 15, got %d (%v)", value, err)
+	}
+}
+
+func TestRandomDevice(t *testing.T) {
+	vm := deviceMachine(t, "", nil, time.Now)
+	mem := vm.Memory()
+
+	first, err := mem.GetInt(testRandom)
+	if err != nil || first < 0 {
+		t.Errorf("Expected a non-negative int, got %d (%v)", first, err)
+	}
+	f, err := mem.GetFloat(testRandom)
+	if err != nil || f < 0 || f >= 1 {
+		t.Errorf("Expected a float in [0, 1), got %g (%v)", f, err)
+	}
+
+	// Seeding again repeats the sequence
+	err = mem.PutInt(testRandom, 1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	again, err := mem.GetInt(testRandom)
+	if err != nil || again != first {
+		t.Errorf("Expected %d, got %d (%v)", first, again, err)
+	}
+}
+
+func TestDeviceFaults(t *testing.T) {
+	vm := deviceMachine(t, "", nil, time.Now)
+	mem := vm.Memory()
+
+	tests := []struct {
+		name   string
+		access func() error
+		kind   error
+	}{
+		{"int on console", func() error { _, err := mem.GetInt(testConsole); return err }, ErrDevice},
+		{"across the edge", func() error { return mem.PutInt(testConsole-4, 1) }, ErrDevice},
+		{"int16 on timer", func() error { _, err := mem.GetInt16(testTimer); return err }, ErrDevice},
+		{"string on random", func() error { return mem.PutString(testRandom-IntSize, "abc") }, ErrDevice},
+		{"console status", func() error { return mem.PutByte(testConsole+1, 1) }, ErrDevice},
+		{"overlap", func() error { return mem.AttachDevice(testTimer+4, 8, NewTimerDevice()) }, ErrDevice},
+		{"outside memory", func() error { return mem.AttachDevice(MEMORY_SIZE-4, 8, NewTimerDevice()) }, ErrIllegalAddress},
+		{"detach nothing", func() error { return mem.DetachDevice(testConsole + 1) }, ErrIllegalAddress},
+	}
+
+	for _, test := range tests {
+		err := test.access()
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.kind, err)
+		}
+	}
+
+	// A fault of an instruction carries the address and program pointer
+	p := NewProgram()
+	p.WriteByte(0x21)       // Opcode: get-int()
+	p.WriteInt(testConsole) // Operant: console
+	p.WriteByte(0x00)       // Opcode: end
+
+	err := p.RunOn(vm, nil, nil)
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || vmErr.Kind != ErrDevice || vmErr.Address != testConsole || vmErr.ProgramPointer != 0 {
+		t.Errorf("Unexpected fault %v", err)
+	}
+
+	// Once detached it is plain memory again
+	err = mem.DetachDevice(testConsole)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = p.RunOn(vm, nil, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
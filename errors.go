@@ -0,0 +1,147 @@
+package virtualmachine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// -- Fault kinds, use errors.Is to check which one a VMError holds -------------------------------------------------------------
+
+// ErrMemory is raised when reading or writing outside of memory
+var ErrMemory = errors.New("memory error")
+
+// ErrIllegalAddress is raised when jumping, calling or returning to an address outside of memory
+var ErrIllegalAddress = errors.New("illegal address")
+
+// ErrUnknownOpcode is raised when the program pointer runs into a byte that isn't an instruction
+var ErrUnknownOpcode = errors.New("unknown opcode")
+
+// ErrStackOverflow is raised when pushing beyond the size of the stack
+var ErrStackOverflow = errors.New("stack overflow")
+
+// ErrStackUnderflow is raised when popping from an empty stack
+var ErrStackUnderflow = errors.New("stack underflow")
+
+// ErrStackBlocked is raised on every stack access after an overflow or underflow
+var ErrStackBlocked = errors.New("stack blocked")
+
+// ErrDivisionByZero is raised when a byte or int (or, when trapped, a float) is divided by zero
+var ErrDivisionByZero = errors.New("division by zero")
+
+// ErrIntegerOverflow is raised when the result of an int operation doesn't fit, like math.MinInt / -1
+var ErrIntegerOverflow = errors.New("integer overflow")
+
+// ErrInvalidConversion is raised when a value has no counterpart in the type it is converted to, like NaN as an int
+var ErrInvalidConversion = errors.New("invalid conversion")
+
+// ErrIndexOutOfRange is raised when a string is indexed or sliced beyond its length
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// ErrHeapExhausted is raised when the heap has no room left for an allocation
+var ErrHeapExhausted = errors.New("heap exhausted")
+
+// ErrHeapCollision is raised when a block on the heap would overlap the stack
+var ErrHeapCollision = errors.New("heap collides with the stack")
+
+// ErrDoubleFree is raised when a block on the heap is freed or reallocated after it was freed
+var ErrDoubleFree = errors.New("double free")
+
+// ErrUseAfterFree is raised when memory of a freed block is read or written, only detected in heap debug mode
+var ErrUseAfterFree = errors.New("use after free")
+
+// ErrEndOfInput is raised when reading a number after the input ended
+var ErrEndOfInput = errors.New("end of input")
+
+// ErrIO is raised when reading the input or writing the output fails, the cause is the error of the reader or writer
+var ErrIO = errors.New("input/output error")
+
+// ErrDevice is raised when a device mapped into memory refuses a read or write, the cause tells why
+var ErrDevice = errors.New("device error")
+
+// ErrProtection is raised when reading, writing or executing memory that doesn't permit it, the cause tells which
+var ErrProtection = errors.New("memory protection violation")
+
+// ErrUnknownHostFunc is raised by a syscall of a host function that isn't registered
+var ErrUnknownHostFunc = errors.New("unknown host function")
+
+// ErrHostFunc is raised when a host function called by syscall fails, the cause is the error it returned
+var ErrHostFunc = errors.New("host function error")
+
+// ErrBudgetExhausted is raised when the program executed the maximum number of instructions it was given
+var ErrBudgetExhausted = errors.New("instruction budget exhausted")
+
+// ErrCancelled is raised when the context of RunContext is done, the cause is the error of the context
+var ErrCancelled = errors.New("cancelled")
+
+// ErrIllegalOperant is raised when the operant of an instruction is out of its range, like a negative count for drop-n
+var ErrIllegalOperant = errors.New("illegal operant")
+
+// faultKinds numbers the fault kinds for a fault handler, new kinds go at the end so the numbers don't change
+var faultKinds = []error{
+	ErrMemory, ErrIllegalAddress, ErrUnknownOpcode, ErrStackOverflow, ErrStackUnderflow, ErrStackBlocked,
+	ErrDivisionByZero, ErrIntegerOverflow, ErrInvalidConversion,
+	ErrIndexOutOfRange, ErrHeapExhausted, ErrHeapCollision, ErrDoubleFree, ErrUseAfterFree,
+	ErrEndOfInput, ErrIO, ErrDevice, ErrProtection,
+	ErrUnknownHostFunc, ErrHostFunc,
+	ErrBudgetExhausted, ErrCancelled,
+	ErrIllegalOperant,
+}
+
+// FaultCode returns the number a fault handler gets for kind, counting from 1 in the order of the Err... values above. 0
+// means kind isn't one of them.
+func FaultCode(kind error) int {
+	for i, k := range faultKinds {
+		if errors.Is(kind, k) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// -- VMError -------------------------------------------------------------------------------------------------------------------
+
+// VMError is a fault of the program running on the virtual machine. Memory and Stack raise it without knowing the program,
+// Step fills in the program pointer, opcode and stack pointer before returning it.
+type VMError struct {
+	Kind           error  // one of the Err... values
+	ProgramPointer int    // address of the faulting instruction, -1 when raised outside of a program
+	Opcode         Opcode // opcode of the faulting instruction
+	Address        int    // memory address or jump target involved, -1 when there is none
+	StackPointer   int    // stack pointer at the time of the fault
+	Cause          error  // underlying error, if any
+}
+
+// newVMError creates a fault that is not (yet) tied to an instruction
+func newVMError(kind error, address int) *VMError {
+	return &VMError{Kind: kind, ProgramPointer: -1, Address: address}
+}
+
+func (e *VMError) Error() string {
+	text := e.Kind.Error()
+	if e.Address >= 0 {
+		text += fmt.Sprintf(" %04X", e.Address)
+	} else if e.Address != -1 {
+		text += fmt.Sprintf(" %d", e.Address)
+	}
+
+	if e.ProgramPointer >= 0 {
+		text += fmt.Sprintf(" at %04X (opcode %02X, sp %d)", e.ProgramPointer, e.Opcode, e.StackPointer)
+	}
+
+	if e.Cause != nil {
+		text += ": " + e.Cause.Error()
+	}
+
+	return text
+}
+
+// Is makes errors.Is match the kind of fault
+func (e *VMError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap gives access to the underlying error
+func (e *VMError) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,118 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+)
+
+// runFault runs the program and returns the VMError it should end with
+func runFault(t *testing.T, p *Program, kind error) *VMError {
+	err := p.Run(nil, nil)
+	if !errors.Is(err, kind) {
+		t.Fatalf("Expected: %v, got %v", kind, err)
+	}
+
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) {
+		t.Fatalf("Expected a VMError, got %T", err)
+	}
+
+	return vmErr
+}
+
+func TestVMErrorUnknownOpcode(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x20) // Operant: 0x20
+	p.WriteByte(0x01) // Opcode: <unknown>
+
+	vmErr := runFault(t, p, ErrUnknownOpcode)
+	if vmErr.ProgramPointer != 2 || vmErr.Opcode != 0x01 || vmErr.StackPointer != 1 || vmErr.Address != -1 {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+}
+
+func TestVMErrorIllegalAddress(t *testing.T) {
+	testAddress := MEMORY_SIZE + 1
+
+	p := NewProgram()
+	p.WriteByte(0xE1)       // Opcode: jmp()
+	p.WriteInt(testAddress) // Operant: testAddress
+
+	vmErr := runFault(t, p, ErrIllegalAddress)
+	if vmErr.ProgramPointer != 0 || vmErr.Opcode != 0xE1 || vmErr.Address != testAddress {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+	if vmErr.Error() != "illegal address 0101 at 0000 (opcode E1, sp 0)" {
+		t.Errorf("Unexpected message %s", vmErr.Error())
+	}
+}
+
+func TestVMErrorStackOverflow(t *testing.T) {
+	// Calls itself until the stack runs out
+	p := NewProgram()
+	p.WriteByte(0xF9) // Opcode: call()
+	p.WriteInt(0)     // Operant: 0
+
+	vmErr := runFault(t, p, ErrStackOverflow)
+	if vmErr.ProgramPointer != 0 || vmErr.Opcode != 0xF9 || vmErr.StackPointer != STACK_SIZE {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+	if vmErr.Address != MEMORY_SIZE {
+		t.Errorf("Expected address %04X, got %04X", MEMORY_SIZE, vmErr.Address)
+	}
+}
+
+func TestVMErrorStackUnderflow(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x20) // Operant: 0x20
+	p.WriteByte(0x41) // Opcode: add-int
+
+	vmErr := runFault(t, p, ErrStackUnderflow)
+	if vmErr.ProgramPointer != 2 || vmErr.Opcode != 0x41 {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+}
+
+func TestVMErrorMemory(t *testing.T) {
+	// Outside of a program there is no program pointer to report
+	mem := NewMemory(MEMORY_SIZE)
+	_, err := mem.GetInt(MEMORY_SIZE - 1)
+	if !errors.Is(err, ErrMemory) {
+		t.Fatalf("Expected: memory error, got %v", err)
+	}
+	if err.Error() != "memory error 00FF" {
+		t.Errorf("Unexpected message %s", err.Error())
+	}
+
+	// Inside a program there is
+	p := NewProgram()
+	p.WriteByte(0x21)           // Opcode: get-int()
+	p.WriteInt(MEMORY_SIZE - 4) // Operant: crosses the end of memory
+
+	vmErr := runFault(t, p, ErrMemory)
+	if vmErr.ProgramPointer != 0 || vmErr.Opcode != 0x21 || vmErr.Address != MEMORY_SIZE-4 {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+}
+
+func TestFaultCode(t *testing.T) {
+	tests := []struct {
+		kind error
+		code int
+	}{
+		{ErrMemory, 1},
+		{ErrDivisionByZero, 7},
+		{newVMError(ErrEndOfInput, -1), 15},
+		{ErrCancelled, 22},
+		{errors.New("not a fault"), 0},
+	}
+
+	for _, test := range tests {
+		code := FaultCode(test.kind)
+		if code != test.code {
+			t.Errorf("Expected %d for %v, got %d", test.code, test.kind, code)
+		}
+	}
+}
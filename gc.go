@@ -0,0 +1,154 @@
+package virtualmachine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The garbage collector frees the blocks on the heap a program can't reach anymore. It marks every block whose address is
+// held by an int on the stack, by a registered global slot or by an int in a marked data block, then sweeps the unmarked
+// ones. The search is conservative: any int that happens to hold the address of a block keeps it, strings are never
+// searched. The collector runs when the bytes allocated since the last collection reach the threshold, and before an
+// allocation that doesn't fit gives up.
+
+// gc is the state of the garbage collector of a heap
+type gc struct {
+	threshold int   // bytes allocated between collections, 0 when off
+	allocated int   // bytes allocated since the last collection
+	roots     []int // addresses of global slots holding references
+	stats     GCStats
+}
+
+// GCStats tells what the garbage collector did
+type GCStats struct {
+	Collections int // number of collections
+	Freed       int // blocks freed by collections
+	FreedBytes  int // bytes freed by collections, without the headers
+	Live        int // bytes in use after the last collection
+}
+
+// -- Garbage collector API -----------------------------------------------------------------------------------------------------
+
+// SetGCThreshold switches the garbage collector on, it collects after threshold bytes were allocated. 0 switches it off.
+func (vm *VirtualMachine) SetGCThreshold(threshold int) error {
+	if threshold < 0 {
+		return fmt.Errorf("illegal garbage collection threshold")
+	}
+
+	vm.heap.gc.threshold = threshold
+	return nil
+}
+
+// AddGCRoot registers the int at address as a global slot, the block it refers to is never collected
+func (vm *VirtualMachine) AddGCRoot(address int) error {
+	if address < 0 || address+IntSize > vm.memory.Size() {
+		return newVMError(ErrIllegalAddress, address)
+	}
+
+	for _, root := range vm.heap.gc.roots {
+		if root == address {
+			return nil
+		}
+	}
+
+	vm.heap.gc.roots = append(vm.heap.gc.roots, address)
+	return nil
+}
+
+// RemoveGCRoot removes the global slot at address
+func (vm *VirtualMachine) RemoveGCRoot(address int) {
+	roots := vm.heap.gc.roots
+	for i, root := range roots {
+		if root == address {
+			vm.heap.gc.roots = append(roots[:i], roots[i+1:]...)
+			return
+		}
+	}
+}
+
+// CollectGarbage runs the garbage collector now, whether it is switched on or not
+func (vm *VirtualMachine) CollectGarbage() error {
+	return vm.heap.collect(-1)
+}
+
+// GCStats tells what the garbage collector did so far
+func (vm *VirtualMachine) GCStats() GCStats {
+	return vm.heap.gc.stats
+}
+
+// -- Mark and sweep ------------------------------------------------------------------------------------------------------------
+
+// collect frees the blocks that can't be reached, keeping the block at keep when it isn't -1
+func (h *Heap) collect(keep int) error {
+	// Find the allocated blocks, a header the program overwrote faults rather than sending the walk off the heap
+	sizes := make(map[int]int)
+	objects := make(map[int]HeapObject)
+	for header := h.start; header < h.top; {
+		size, flags, err := h.header(header)
+		if err != nil {
+			return err
+		}
+		if flags&heapUsedFlag != 0 {
+			sizes[header+heapHeaderSize] = size
+			objects[header+heapHeaderSize] = HeapObject(flags >> heapTypeShift)
+		}
+		header += heapHeaderSize + size
+	}
+
+	// Mark what the roots refer to, and what that refers to. Memory is read as it is, so watches and devices don't see it.
+	marked := make(map[int]bool)
+	var work []int
+	mark := func(address int, fits bool) {
+		if _, ok := sizes[address]; ok && fits && !marked[address] {
+			marked[address] = true
+			work = append(work, address)
+		}
+	}
+
+	mark(keep, true)
+	if h.stack != nil {
+		for at := h.stack.offset; at+IntSize <= h.stack.offset+h.stack.pointer; at++ {
+			mark(decodeInt(h.memory.memory[at:]))
+		}
+	}
+	for _, root := range h.gc.roots {
+		if root >= 0 && root+IntSize <= len(h.memory.memory) {
+			mark(decodeInt(h.memory.memory[root:]))
+		}
+	}
+
+	for len(work) > 0 {
+		address := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		if objects[address] != HeapData {
+			continue
+		}
+		for at := address; at+IntSize <= address+sizes[address]; at++ {
+			mark(decodeInt(h.memory.memory[at:]))
+		}
+	}
+
+	// Sweep the rest, in order so freed neighbours merge
+	var garbage []int
+	for address := range sizes {
+		if !marked[address] {
+			garbage = append(garbage, address)
+		}
+	}
+	sort.Ints(garbage)
+
+	for _, address := range garbage {
+		err := h.free(address)
+		if err != nil {
+			return err
+		}
+		h.gc.stats.Freed++
+		h.gc.stats.FreedBytes += sizes[address]
+	}
+
+	h.gc.stats.Collections++
+	h.gc.stats.Live = h.inUse
+	h.gc.allocated = 0
+	return nil
+}
@@ -0,0 +1,230 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+)
+
+func gcMachine(t *testing.T, threshold int) *VirtualMachine {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.GCThreshold = threshold
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return vm
+}
+
+func TestGC(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x0D) // Opcode: pop-int
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(16)    // Operant: 16
+	p.WriteByte(0xC4) // Opcode: gc
+	p.WriteByte(0x00) // Opcode: end
+
+	// The first block is lost, the second is still on the stack
+	s := NewBuffer()
+	s.WriteInt(p.Size() + heapHeaderSize + 8 + heapHeaderSize)
+
+	vm := gcMachine(t, 0)
+	err := p.RunOn(vm, s, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stats := vm.GCStats()
+	if stats.Collections != 1 || stats.Freed != 1 || stats.FreedBytes != 8 || stats.Live != 16 {
+		t.Errorf("Unexpected garbage collection statistics %+v", stats)
+	}
+}
+
+func TestGCReachable(t *testing.T) {
+	// Leave room for the global slot below the heap
+	vm := gcMachine(t, 0)
+	err := vm.Load(make([]byte, IntSize))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	heap := vm.Heap()
+
+	alloc := func(size int, object HeapObject) int {
+		address, err := heap.AllocObject(size, object)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		return address
+	}
+	put := func(address int, value int) {
+		err := vm.Memory().PutInt(address, value)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	// A global slot refers to data, which refers to more data. The stack refers to a string, which looks like it refers to
+	// a block but strings hold no references.
+	global := heap.Start() - IntSize
+	inner := alloc(IntSize, HeapData)
+	outer := alloc(2*IntSize, HeapData)
+	lost := alloc(IntSize, HeapData)
+	text := alloc(IntSize, HeapString)
+	garbage := alloc(4, HeapData)
+
+	put(outer+IntSize, inner)
+	put(text, lost)
+	put(global, outer)
+
+	err = vm.AddGCRoot(global)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Stack().PushInt(text)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = vm.CollectGarbage()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, address := range []int{inner, outer, text} {
+		_, _, err = heap.block(address)
+		if err != nil {
+			t.Errorf("Expected block at %d to be kept, got %v", address, err)
+		}
+	}
+	for _, address := range []int{lost, garbage} {
+		_, _, err = heap.block(address)
+		if err == nil {
+			t.Errorf("Expected block at %d to be collected", address)
+		}
+	}
+
+	// Without the global slot the data goes too
+	vm.RemoveGCRoot(global)
+	err = vm.CollectGarbage()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stats := vm.GCStats()
+	if stats.Collections != 2 || stats.Freed != 4 || stats.Live != IntSize {
+		t.Errorf("Unexpected garbage collection statistics %+v", stats)
+	}
+}
+
+func TestGCUnaligned(t *testing.T) {
+	vm := gcMachine(t, 0)
+	heap := vm.Heap()
+
+	// Data can keep a reference at any offset, like the stack can
+	inner, err := heap.AllocObject(IntSize, HeapData)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	holder, err := heap.AllocObject(2*IntSize+1, HeapData)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Memory().PutInt(holder+1, inner)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Stack().PushInt(holder)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = vm.CollectGarbage()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, _, err = heap.block(inner)
+	if err != nil || vm.GCStats().Freed != 0 {
+		t.Errorf("Expected the block at %d to be kept, got %v with %+v", inner, err, vm.GCStats())
+	}
+}
+
+func TestGCThreshold(t *testing.T) {
+	p := NewProgram()
+	for i := 0; i < 8; i++ {
+		p.WriteByte(0xC0) // Opcode: alloc-n
+		p.WriteInt(8)     // Operant: 8
+		p.WriteByte(0x0D) // Opcode: pop-int
+	}
+	p.WriteByte(0x00) // Opcode: end
+
+	vm := gcMachine(t, 32)
+	err := p.RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// A collection every 4 blocks frees them, after which the space is used again
+	stats := vm.GCStats()
+	if stats.Collections != 1 || stats.Freed != 4 {
+		t.Errorf("Unexpected garbage collection statistics %+v", stats)
+	}
+	if vm.Heap().Top() != vm.Heap().Start()+4*(heapHeaderSize+8) {
+		t.Errorf("Expected the heap to be reused, top is %d", vm.Heap().Top()-vm.Heap().Start())
+	}
+}
+
+func TestGCRealloc(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x0D) // Opcode: pop-int
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(64)    // Operant: 64
+	p.WriteByte(0xC3) // Opcode: realloc
+	p.WriteByte(0x00) // Opcode: end
+
+	// The block being resized is popped when the collection runs, but is kept; the lost block makes room for it
+	s := NewBuffer()
+	s.WriteInt(p.Size() + heapHeaderSize + 8 + heapHeaderSize)
+
+	vm := gcMachine(t, 16)
+	err := p.RunOn(vm, s, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stats := vm.GCStats()
+	if stats.Collections != 1 || stats.Freed != 1 {
+		t.Errorf("Unexpected garbage collection statistics %+v", stats)
+	}
+}
+
+func TestGCCorrupt(t *testing.T) {
+	n := 1 + IntSize
+	header := 3*n + 3
+
+	// The program overwrites the header of its block, the collector faults instead of running off the heap
+	p := NewProgram()
+	p.WriteByte(0xC0)  // Opcode: alloc-n
+	p.WriteInt(8)      // Operant: 8
+	p.WriteByte(0x0D)  // Opcode: pop-int
+	p.WriteByte(0x09)  // Opcode: push-int
+	p.WriteInt(-9)     // Operant: -9
+	p.WriteByte(0x29)  // Opcode: put-int()
+	p.WriteInt(header) // Operant: header
+	p.WriteByte(0xC4)  // Opcode: gc
+	p.WriteByte(0x00)  // Opcode: end
+
+	err := p.RunOn(gcMachine(t, 0), nil, nil)
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || vmErr.Kind != ErrIllegalAddress || vmErr.Address != header ||
+		vmErr.ProgramPointer != 3*n+1 || vmErr.Opcode != 0xC4 {
+		t.Errorf("Expected: illegal address of the header at %d by gc at %04X, got %v", header, 3*n+1, err)
+	}
+}
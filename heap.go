@@ -0,0 +1,452 @@
+package virtualmachine
+
+import "errors"
+
+// The heap is the memory between the loaded program and the stack. Every block on it starts with a header: an int holding
+// the size of the block, without the header, followed by a byte of flags. The blocks follow each other up to the top of
+// the heap, above it the heap is free. Freed blocks below the top are reused first fit, a block that is much larger than
+// needed is split. In debug mode freed blocks are never reused: they stay in quarantine, guarded against reads and writes,
+// so a second free or a use after free faults.
+
+const heapHeaderSize = IntSize + ByteSize
+
+const (
+	heapUsedFlag       = 1 << 0 // the block is allocated
+	heapQuarantineFlag = 1 << 1 // the block was freed in debug mode and is never used again
+	heapTypeShift      = 4      // the upper 4 bits hold the HeapObject type of an allocated block
+)
+
+// HeapObject is the type of a block on the heap, it tells the garbage collector where to look for references
+type HeapObject byte
+
+const (
+	HeapData   HeapObject = iota // any data, every int in it may be a reference to another block
+	HeapString                   // a string, it holds no references
+)
+
+// heapMinSplit is the smallest free block worth splitting off a reused block
+const heapMinSplit = heapHeaderSize + IntSize
+
+type Heap struct {
+	memory *Memory
+	stack  *Stack // blocks must stay clear of it
+	start  int    // address of the first block
+	end    int    // first address beyond the heap
+	top    int    // first address beyond the last block
+	debug  bool   // quarantine freed blocks and check every free
+
+	gc gc // the garbage collector, off unless it has a threshold
+
+	allocs   int // successful allocations, reallocations excluded
+	frees    int // successful frees, reallocations excluded
+	reallocs int // successful reallocations
+	failures int // allocations and reallocations that didn't fit
+	inUse    int // bytes in allocated blocks
+	peak     int // highest inUse so far
+}
+
+// HeapStats tells how the heap is used
+type HeapStats struct {
+	Allocs      int // successful allocations
+	Frees       int // successful frees
+	Reallocs    int // successful reallocations
+	Failures    int // allocations and reallocations that didn't fit
+	InUse       int // bytes in allocated blocks, without their headers
+	Blocks      int // number of allocated blocks
+	Peak        int // highest number of bytes in use so far
+	Free        int // bytes in free blocks and above the top, without the headers of the free blocks
+	Quarantined int // bytes in freed blocks kept out of use by debug mode
+}
+
+// -- Allocation ----------------------------------------------------------------------------------------------------------------
+
+// Alloc reserves size bytes of data and returns the address of the first one
+func (h *Heap) Alloc(size int) (address int, err error) {
+	return h.AllocObject(size, HeapData)
+}
+
+// AllocObject reserves size bytes for an object of type object and returns the address of the first one
+func (h *Heap) AllocObject(size int, object HeapObject) (address int, err error) {
+	address, err = h.alloc(size, heapUsedFlag|byte(object)<<heapTypeShift, -1)
+	if err != nil {
+		return 0, err
+	}
+
+	h.allocs++
+	return address, nil
+}
+
+// Free gives the block at address back, address is what Alloc or Realloc returned
+func (h *Heap) Free(address int) error {
+	err := h.free(address)
+	if err != nil {
+		return err
+	}
+
+	h.frees++
+	return nil
+}
+
+// Realloc resizes the block at address to size bytes and returns its new address, the contents are kept up to the smaller
+// of both sizes. The block stays where it is when it can, except in debug mode where it always moves so the old address
+// can't be used anymore.
+func (h *Heap) Realloc(address int, size int) (int, error) {
+	oldSize, flags, err := h.block(address)
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 {
+		h.failures++
+		return 0, newVMError(ErrHeapExhausted, -1)
+	}
+
+	if !h.debug && h.resize(address, oldSize, size, flags) {
+		h.reallocs++
+		return address, nil
+	}
+
+	newAddress, err := h.alloc(size, flags, address)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < oldSize && i < size; i++ {
+		value, err := h.memory.GetByte(address + i)
+		if err != nil {
+			return 0, err
+		}
+		err = h.memory.PutByte(newAddress+i, value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = h.free(address)
+	if err != nil {
+		return 0, err
+	}
+
+	h.reallocs++
+	return newAddress, nil
+}
+
+// alloc finds room for size bytes, first in the free blocks and then on top, and gives the block flags. Garbage collection
+// keeps the block at keep, when it isn't -1.
+func (h *Heap) alloc(size int, flags byte, keep int) (int, error) {
+	if size < 0 {
+		h.failures++
+		return 0, newVMError(ErrHeapExhausted, -1)
+	}
+
+	collected := false
+	if h.gc.threshold > 0 && h.gc.allocated >= h.gc.threshold {
+		err := h.collect(keep)
+		if err != nil {
+			return 0, err
+		}
+		collected = true
+	}
+
+	address, err := h.place(size, flags)
+	if errors.Is(err, ErrHeapExhausted) && h.gc.threshold > 0 && !collected {
+		err = h.collect(keep)
+		if err != nil {
+			return 0, err
+		}
+		address, err = h.place(size, flags)
+	}
+	if err != nil {
+		if errors.Is(err, ErrHeapExhausted) || errors.Is(err, ErrHeapCollision) {
+			h.failures++
+		}
+		return 0, err
+	}
+
+	return address, nil
+}
+
+// place puts a block of size bytes in the first free block large enough, or on top
+func (h *Heap) place(size int, flags byte) (int, error) {
+	for header := h.start; header < h.top; {
+		blockSize, blockFlags, err := h.header(header)
+		if err != nil {
+			return 0, err
+		}
+
+		if blockFlags == 0 {
+			blockSize, err = h.merge(header, blockSize)
+			if err != nil {
+				return 0, err
+			}
+			if blockSize >= size {
+				return h.use(header, blockSize, size, flags)
+			}
+		}
+
+		header += heapHeaderSize + blockSize
+	}
+
+	if size > h.end-h.top-heapHeaderSize {
+		return 0, newVMError(ErrHeapExhausted, -1)
+	}
+	if h.collides(h.top, h.top+heapHeaderSize+size) {
+		return 0, newVMError(ErrHeapCollision, h.top)
+	}
+
+	h.top += heapHeaderSize + size
+	return h.use(h.top-heapHeaderSize-size, size, size, flags)
+}
+
+// use allocates size bytes in the free block of blockSize bytes at header, splitting off what is left when it is worth it
+func (h *Heap) use(header int, blockSize int, size int, flags byte) (int, error) {
+	if blockSize-size >= heapMinSplit {
+		err := h.setHeader(header+heapHeaderSize+size, blockSize-size-heapHeaderSize, 0)
+		if err != nil {
+			return 0, err
+		}
+		blockSize = size
+	}
+
+	err := h.setHeader(header, blockSize, flags)
+	if err != nil {
+		return 0, err
+	}
+
+	h.gc.allocated += blockSize
+	h.inUse += blockSize
+	if h.inUse > h.peak {
+		h.peak = h.inUse
+	}
+	return header + heapHeaderSize, nil
+}
+
+// free marks the block at address free, or quarantines it in debug mode
+func (h *Heap) free(address int) error {
+	size, _, err := h.block(address)
+	if err != nil {
+		return err
+	}
+	header := address - heapHeaderSize
+
+	h.inUse -= size
+	if h.debug {
+		err = h.setHeader(header, size, heapQuarantineFlag)
+		if err != nil {
+			return err
+		}
+		h.memory.guard(address, size, ErrUseAfterFree)
+		return nil
+	}
+
+	err = h.setHeader(header, size, 0)
+	if err != nil {
+		return err
+	}
+	size, err = h.merge(header, size)
+	if err != nil {
+		return err
+	}
+	if address+size == h.top {
+		return h.trim()
+	}
+
+	return nil
+}
+
+// resize changes the size of the block at address in place if it is the last one or when it shrinks, keeping its flags
+func (h *Heap) resize(address int, oldSize int, size int, flags byte) bool {
+	header := address - heapHeaderSize
+
+	if address+oldSize == h.top {
+		if size > h.end-address || h.collides(address, address+size) {
+			return false
+		}
+		if h.setHeader(header, size, flags) != nil {
+			return false
+		}
+		h.top = address + size
+		if size > oldSize {
+			h.gc.allocated += size - oldSize
+		}
+	} else if oldSize-size >= heapMinSplit {
+		if h.setHeader(address+size, oldSize-size-heapHeaderSize, 0) != nil ||
+			h.setHeader(header, size, flags) != nil {
+			return false
+		}
+	} else {
+		return size <= oldSize
+	}
+
+	h.inUse += size - oldSize
+	if h.inUse > h.peak {
+		h.peak = h.inUse
+	}
+	return true
+}
+
+// -- Blocks --------------------------------------------------------------------------------------------------------------------
+
+// block checks that address is an allocated block and returns its size and flags. In debug mode address has to be the
+// start of a block, otherwise only its header is checked.
+func (h *Heap) block(address int) (int, byte, error) {
+	header := address - heapHeaderSize
+	if header < h.start || address > h.top {
+		return 0, 0, newVMError(ErrIllegalAddress, address)
+	}
+
+	if h.debug {
+		found := false
+		for at := h.start; at < header && !found; {
+			size, _, err := h.header(at)
+			if err != nil {
+				return 0, 0, err
+			}
+			at += heapHeaderSize + size
+			found = at == header
+		}
+		if header != h.start && !found {
+			return 0, 0, newVMError(ErrIllegalAddress, address)
+		}
+	}
+
+	size, flags, err := h.header(header)
+	if err != nil {
+		return 0, 0, err
+	}
+	if flags&heapUsedFlag == 0 {
+		return 0, 0, newVMError(ErrDoubleFree, address)
+	}
+
+	return size, flags, nil
+}
+
+// merge joins the free block at header with the free blocks following it and returns its new size
+func (h *Heap) merge(header int, size int) (int, error) {
+	for next := header + heapHeaderSize + size; next < h.top; next = header + heapHeaderSize + size {
+		nextSize, flags, err := h.header(next)
+		if err != nil {
+			return 0, err
+		}
+		if flags != 0 {
+			break
+		}
+		size += heapHeaderSize + nextSize
+	}
+
+	return size, h.setHeader(header, size, 0)
+}
+
+// trim lowers the top to the end of the last block in use or in quarantine
+func (h *Heap) trim() error {
+	top := h.start
+	for header := h.start; header < h.top; {
+		size, flags, err := h.header(header)
+		if err != nil {
+			return err
+		}
+		header += heapHeaderSize + size
+		if flags != 0 {
+			top = header
+		}
+	}
+
+	h.top = top
+	return nil
+}
+
+// collides tells if the memory from start up to end overlaps the stack
+func (h *Heap) collides(start int, end int) bool {
+	return h.stack != nil && start < h.stack.offset+h.stack.size && h.stack.offset < end
+}
+
+// header reads the header at header. The heap lives in memory the program can write, so a size that runs past the top
+// faults rather than sending a walk over the heap off into the rest of memory.
+func (h *Heap) header(header int) (size int, flags byte, err error) {
+	size, err = h.memory.GetInt(header)
+	if err != nil {
+		return 0, 0, err
+	}
+	if size < 0 || size > h.top-header-heapHeaderSize {
+		return 0, 0, newVMError(ErrIllegalAddress, header)
+	}
+	flags, err = h.memory.GetByte(header + IntSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return size, flags, nil
+}
+
+func (h *Heap) setHeader(header int, size int, flags byte) error {
+	err := h.memory.PutInt(header, size)
+	if err != nil {
+		return err
+	}
+
+	return h.memory.PutByte(header+IntSize, flags)
+}
+
+// -- Information ---------------------------------------------------------------------------------------------------------------
+
+// Start returns the address of the first block on the heap
+func (h *Heap) Start() int {
+	return h.start
+}
+
+// End returns the first address beyond the heap
+func (h *Heap) End() int {
+	return h.end
+}
+
+// Top returns the first address beyond the last block on the heap
+func (h *Heap) Top() int {
+	return h.top
+}
+
+// Debug tells if freed blocks are quarantined and every free is checked
+func (h *Heap) Debug() bool {
+	return h.debug
+}
+
+// Stats walks the heap and tells how it is used
+func (h *Heap) Stats() (HeapStats, error) {
+	stats := HeapStats{
+		Allocs:   h.allocs,
+		Frees:    h.frees,
+		Reallocs: h.reallocs,
+		Failures: h.failures,
+		Peak:     h.peak,
+		Free:     h.end - h.top,
+	}
+
+	for header := h.start; header < h.top; {
+		size, flags, err := h.header(header)
+		if err != nil {
+			return stats, err
+		}
+
+		switch {
+		case flags&heapUsedFlag != 0:
+			stats.InUse += size
+			stats.Blocks++
+		case flags&heapQuarantineFlag != 0:
+			stats.Quarantined += size
+		default:
+			stats.Free += size
+		}
+
+		header += heapHeaderSize + size
+	}
+
+	return stats, nil
+}
+
+// -- Companion functions -------------------------------------------------------------------------------------------------------
+
+// newHeap places an empty heap on memory between start and end, clear of stack
+func newHeap(memory *Memory, stack *Stack, start int, end int) *Heap {
+	if end < start {
+		end = start
+	}
+
+	return &Heap{memory: memory, stack: stack, start: start, end: end, top: start}
+}
@@ -0,0 +1,291 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHeapAlloc(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+	heap := newHeap(mem, nil, 16, 64)
+
+	address, err := heap.Alloc(10)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if address != 16+heapHeaderSize || heap.Top() != address+10 {
+		t.Errorf("Expected a block at %d, got %d with top %d", 16+heapHeaderSize, address, heap.Top())
+	}
+
+	size, err := mem.GetInt(16)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	flags, err := mem.GetByte(16 + IntSize)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if size != 10 || flags != heapUsedFlag {
+		t.Errorf("Expected a used block of 10 bytes, got %d bytes with flags %02X", size, flags)
+	}
+
+	// What is left fits exactly
+	_, err = heap.Alloc(64 - heap.Top() - heapHeaderSize)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	_, err = heap.Alloc(0)
+	if !errors.Is(err, ErrHeapExhausted) {
+		t.Errorf("Expected: heap exhausted, got %v", err)
+	}
+}
+
+func TestHeapPlacement(t *testing.T) {
+	program := []byte{0x00, 0x00, 0x00}
+
+	// Between the program and the stack at the top of memory
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(program)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.Heap().Start() != len(program) || vm.Heap().End() != MEMORY_SIZE-STACK_SIZE {
+		t.Errorf("Expected a heap from %d to %d, got %d to %d", len(program), MEMORY_SIZE-STACK_SIZE, vm.Heap().Start(), vm.Heap().End())
+	}
+
+	// Up to the end of memory with the stack at the bottom
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Stack = StackAtBottom
+	cfg.LoadAddress = STACK_SIZE
+
+	vm, err = NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(program)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.Heap().Start() != STACK_SIZE+len(program) || vm.Heap().End() != MEMORY_SIZE {
+		t.Errorf("Expected a heap from %d to %d, got %d to %d", STACK_SIZE+len(program), MEMORY_SIZE, vm.Heap().Start(), vm.Heap().End())
+	}
+}
+
+func TestHeapFree(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+	heap := newHeap(mem, nil, 16, 160)
+
+	a, _ := heap.Alloc(40)
+	b, _ := heap.Alloc(8)
+	c, err := heap.Alloc(8)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// A freed block is reused and split when it is much larger
+	err = heap.Free(a)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	d, err := heap.Alloc(8)
+	if err != nil || d != a {
+		t.Errorf("Expected the freed block at %d, got %d (%v)", a, d, err)
+	}
+	e, err := heap.Alloc(20)
+	if err != nil || e != d+8+heapHeaderSize {
+		t.Errorf("Expected the rest of the freed block at %d, got %d (%v)", d+8+heapHeaderSize, e, err)
+	}
+
+	// Freeing twice is caught by the flags
+	err = heap.Free(b)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = heap.Free(b)
+	if !errors.Is(err, ErrDoubleFree) {
+		t.Errorf("Expected: double free, got %v", err)
+	}
+
+	// Freeing the last block lowers the top below the free blocks before it
+	err = heap.Free(c)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if heap.Top() != b-heapHeaderSize {
+		t.Errorf("Expected the top at %d, got %d", b-heapHeaderSize, heap.Top())
+	}
+
+	// Neighbouring free blocks merge
+	err = heap.Free(d)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = heap.Free(e)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if heap.Top() != 16 {
+		t.Errorf("Expected an empty heap, got the top at %d", heap.Top())
+	}
+
+	// Addresses outside of the heap aren't blocks
+	err = heap.Free(8)
+	if !errors.Is(err, ErrIllegalAddress) {
+		t.Errorf("Expected: illegal address, got %v", err)
+	}
+}
+
+func TestHeapRealloc(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+	heap := newHeap(mem, nil, 16, 160)
+
+	a, _ := heap.Alloc(8)
+	mem.PutInt(a, 42)
+
+	// The last block grows in place
+	b, err := heap.Realloc(a, 16)
+	if err != nil || b != a || heap.Top() != a+16 {
+		t.Errorf("Expected to grow in place at %d, got %d with top %d (%v)", a, b, heap.Top(), err)
+	}
+
+	// Other blocks move, keeping their contents
+	_, err = heap.Alloc(8)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	c, err := heap.Realloc(b, 32)
+	if err != nil || c == b {
+		t.Errorf("Expected to move, got %d (%v)", c, err)
+	}
+	value, err := mem.GetInt(c)
+	if err != nil || value != 42 {
+		t.Errorf("Expected 42, got %d (%v)", value, err)
+	}
+
+	// Shrinking stays in place
+	d, err := heap.Realloc(c, 8)
+	if err != nil || d != c {
+		t.Errorf("Expected to shrink in place at %d, got %d (%v)", c, d, err)
+	}
+
+	_, err = heap.Realloc(d, 1000)
+	if !errors.Is(err, ErrHeapExhausted) {
+		t.Errorf("Expected: heap exhausted, got %v", err)
+	}
+
+	stats, err := heap.Stats()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := HeapStats{Allocs: 2, Reallocs: 3, Failures: 1, InUse: 16, Blocks: 2, Peak: 56,
+		Free: 160 - 16 - 2*heapHeaderSize - 16 - heapHeaderSize}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestHeapDebug(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+	heap := newHeap(mem, nil, 16, 160)
+	heap.debug = true
+
+	a, _ := heap.Alloc(8)
+	b, _ := heap.Alloc(8)
+
+	// Only the start of a block can be freed
+	err := heap.Free(b + 1)
+	if !errors.Is(err, ErrIllegalAddress) {
+		t.Errorf("Expected: illegal address, got %v", err)
+	}
+
+	err = heap.Free(a)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = heap.Free(a)
+	if !errors.Is(err, ErrDoubleFree) {
+		t.Errorf("Expected: double free, got %v", err)
+	}
+	_, err = mem.GetInt(a)
+	if !errors.Is(err, ErrUseAfterFree) {
+		t.Errorf("Expected: use after free, got %v", err)
+	}
+
+	// The freed block isn't reused and reallocation always moves
+	c, _ := heap.Alloc(8)
+	if c == a {
+		t.Errorf("Expected the freed block to stay in quarantine")
+	}
+	d, err := heap.Realloc(c, 4)
+	if err != nil || d == c {
+		t.Errorf("Expected to move, got %d (%v)", d, err)
+	}
+	err = mem.PutByte(c, 1)
+	if !errors.Is(err, ErrUseAfterFree) {
+		t.Errorf("Expected: use after free, got %v", err)
+	}
+
+	stats, err := heap.Stats()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if stats.InUse != 12 || stats.Quarantined != 16 {
+		t.Errorf("Expected 12 bytes in use and 16 in quarantine, got %+v", stats)
+	}
+}
+
+func TestHeapCollision(t *testing.T) {
+	// A heap placed over the stack doesn't hand out blocks in it
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Stack = StackAtBottom
+	cfg.LoadAddress = STACK_SIZE
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	heap := newHeap(vm.memory, vm.stack, STACK_SIZE-16, MEMORY_SIZE)
+	_, err = heap.Alloc(8)
+	if !errors.Is(err, ErrHeapCollision) {
+		t.Errorf("Expected: heap collides with the stack, got %v", err)
+	}
+}
+
+func TestHeapCorrupt(t *testing.T) {
+	for _, size := range []int{-9, -heapHeaderSize, 1000} {
+		mem := NewMemory(MEMORY_SIZE)
+		heap := newHeap(mem, nil, 16, 160)
+
+		first, err := heap.Alloc(8)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		second, err := heap.Alloc(8)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		// The program overwrites the header of the first block, walking the heap faults instead of running off
+		err = mem.PutInt(first-heapHeaderSize, size)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		_, err = heap.Alloc(16)
+		if !errors.Is(err, ErrIllegalAddress) {
+			t.Errorf("%d: expected an illegal address allocating, got %v", size, err)
+		}
+		_, err = heap.Stats()
+		if !errors.Is(err, ErrIllegalAddress) {
+			t.Errorf("%d: expected an illegal address in the statistics, got %v", size, err)
+		}
+		err = heap.Free(second)
+		if !errors.Is(err, ErrIllegalAddress) {
+			t.Errorf("%d: expected an illegal address trimming the top, got %v", size, err)
+		}
+	}
+}
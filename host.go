@@ -0,0 +1,47 @@
+package virtualmachine
+
+import (
+	"errors"
+)
+
+// HostFunc is a Go function a program calls with syscall. It pops its arguments from the stack and pushes its results, and can
+// read and write memory (strings are at the address popped from the stack, see Memory.GetString). A VMError it returns, like a
+// stack underflow from Stack.PopInt, is raised as it is, also when it is wrapped, any other error is raised as ErrHostFunc with
+// it as the cause. Either way the fault is tied to the syscall instruction.
+type HostFunc func(stack *Stack, memory *Memory) error
+
+// RegisterHostFunc makes syscall id call fn, replacing what was registered under id before. A nil fn removes it. Host
+// functions are not part of snapshots and have to be registered again on a restored virtual machine.
+func (vm *VirtualMachine) RegisterHostFunc(id int, fn HostFunc) {
+	if fn == nil {
+		delete(vm.hostFuncs, id)
+		return
+	}
+
+	if vm.hostFuncs == nil {
+		vm.hostFuncs = make(map[int]HostFunc)
+	}
+	vm.hostFuncs[id] = fn
+}
+
+// callHost calls host function id, turning what goes wrong into a fault
+func (vm *VirtualMachine) callHost(id int) error {
+	fn, ok := vm.hostFuncs[id]
+	if !ok {
+		return newVMError(ErrUnknownHostFunc, -1)
+	}
+
+	err := fn(vm.stack, vm.memory)
+	if err == nil {
+		return nil
+	}
+
+	var vmErr *VMError
+	if errors.As(err, &vmErr) {
+		return vmErr
+	}
+
+	hostErr := newVMError(ErrHostFunc, -1)
+	hostErr.Cause = err
+	return hostErr
+}
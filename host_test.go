@@ -0,0 +1,140 @@
+package virtualmachine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// hostMachine creates a virtual machine with host function 1 adding two ints, host function 2 failing and host function 3
+// wrapping a fault
+func hostMachine(t *testing.T) *VirtualMachine {
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vm.RegisterHostFunc(1, func(stack *Stack, memory *Memory) error {
+		b, err := stack.PopInt()
+		if err != nil {
+			return err
+		}
+		a, err := stack.PopInt()
+		if err != nil {
+			return err
+		}
+
+		return stack.PushInt(a + b)
+	})
+	vm.RegisterHostFunc(2, func(stack *Stack, memory *Memory) error {
+		return fmt.Errorf("out of coffee")
+	})
+	vm.RegisterHostFunc(3, func(stack *Stack, memory *Memory) error {
+		_, err := stack.PopFloat()
+		return fmt.Errorf("host function 3: %w", err)
+	})
+
+	return vm
+}
+
+func TestSyscall(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(40)    // Operant: 40
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(2)     // Operant: 2
+	p.WriteByte(0xFA) // Opcode: syscall
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(42)
+
+	err := p.RunOn(hostMachine(t), s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestSyscallMemory(t *testing.T) {
+	vm := hostMachine(t)
+	vm.RegisterHostFunc(3, func(stack *Stack, memory *Memory) error {
+		address, err := stack.PopInt()
+		if err != nil {
+			return err
+		}
+		value, err := memory.GetString(address)
+		if err != nil {
+			return err
+		}
+
+		return stack.PushInt(len(value))
+	})
+
+	p, addresses := stringProgram("hello")
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of "hello"
+	p.WriteByte(0xFA)        // Opcode: syscall
+	p.WriteInt(3)            // Operant: 3
+	p.WriteByte(0x00)        // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(5)
+
+	err := p.RunOn(vm, s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestSyscallFaults(t *testing.T) {
+	tests := []struct {
+		id   int
+		kind error
+	}{
+		{1, ErrStackUnderflow},
+		{2, ErrHostFunc},
+		{3, ErrStackUnderflow},
+		{4, ErrUnknownHostFunc},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x08)   // Opcode: push-byte
+		p.WriteByte(0x01)   // Operant: 1
+		p.WriteByte(0xFA)   // Opcode: syscall
+		p.WriteInt(test.id) // Operant: id
+		p.WriteByte(0x00)   // Opcode: end
+
+		err := p.RunOn(hostMachine(t), nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%d: expected %v, got %v", test.id, test.kind, err)
+			continue
+		}
+
+		var vmErr *VMError
+		if !errors.As(err, &vmErr) || vmErr.Kind != test.kind || vmErr.ProgramPointer != 2 || vmErr.Opcode != 0xFA {
+			t.Errorf("%d: expected a fault of the syscall at 0002, got %v", test.id, err)
+		}
+	}
+
+	// The error of the host function is kept
+	p := NewProgram()
+	p.WriteByte(0xFA) // Opcode: syscall
+	p.WriteInt(2)     // Operant: 2
+	p.WriteByte(0x00) // Opcode: end
+
+	err := p.RunOn(hostMachine(t), nil, nil)
+	if err == nil || err.Error() != "host function error at 0000 (opcode FA, sp 0): out of coffee" {
+		t.Errorf("Unexpected fault %v", err)
+	}
+
+	// Removed host functions are gone
+	vm := hostMachine(t)
+	vm.RegisterHostFunc(2, nil)
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrUnknownHostFunc) {
+		t.Errorf("Expected: unknown host function, got %v", err)
+	}
+}
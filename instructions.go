@@ -0,0 +1,599 @@
+package virtualmachine
+
+//go:generate go run ./cmd/opcodes -readme readme.md
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Opcode is the first byte of every instruction. Instructions on the extended page take two bytes: ExtendedPage followed by
+// the byte selecting the instruction, their opcode is written as 0xFFnn.
+type Opcode uint16
+
+// ExtendedPage is the first byte of the opcodes on the extended page
+const ExtendedPage = 0xFF
+
+// Size returns the number of bytes the opcode takes in memory
+func (o Opcode) Size() int {
+	if o > 0xFF {
+		return 2
+	}
+
+	return 1
+}
+
+// Bytes returns the opcode the way it is stored in memory
+func (o Opcode) Bytes() []byte {
+	if o > 0xFF {
+		return []byte{byte(o >> 8), byte(o)}
+	}
+
+	return []byte{byte(o)}
+}
+
+// DecodeOpcode reads the opcode at the start of code, ok is false when code ends half way an extended opcode
+func DecodeOpcode(code []byte) (opcode Opcode, ok bool) {
+	if len(code) == 0 || (code[0] == ExtendedPage && len(code) < 2) {
+		return 0, false
+	}
+	if code[0] == ExtendedPage {
+		return Opcode(code[0])<<8 | Opcode(code[1]), true
+	}
+
+	return Opcode(code[0]), true
+}
+
+// -- Operants ------------------------------------------------------------------------------------------------------------------
+
+// OperantKind describes what follows the opcode in memory
+type OperantKind int
+
+const (
+	OperantNone    OperantKind = iota // no operant
+	OperantByte                       // push-byte nn
+	OperantInt                        // push-int nn
+	OperantFloat                      // push-float nn
+	OperantAddress                    // get-byte (nn)
+	OperantStack                      // get-byte {nn}
+	OperantInt16                      // push-int16 nn
+	OperantInt32                      // push-int32 nn
+)
+
+// Size returns the number of bytes the operant takes in memory
+func (kind OperantKind) Size() int {
+	switch kind {
+	case OperantByte:
+		return TypeByte.Size()
+	case OperantInt, OperantAddress, OperantStack:
+		return TypeInt.Size()
+	case OperantFloat:
+		return TypeFloat.Size()
+	case OperantInt16:
+		return TypeInt16.Size()
+	case OperantInt32:
+		return TypeInt32.Size()
+	}
+
+	return 0
+}
+
+// Format renders the operant the way the assembler reads it, operant holds exactly Size() bytes
+func (kind OperantKind) Format(operant []byte) string {
+	switch kind {
+	case OperantByte:
+		return fmt.Sprintf("%d", operant[0])
+	case OperantInt:
+		return fmt.Sprintf("%d", decodeInt64(operant))
+	case OperantFloat:
+		return strconv.FormatFloat(decodeFloat(operant), 'g', -1, 64)
+	case OperantAddress:
+		return fmt.Sprintf("(%d)", decodeInt64(operant))
+	case OperantStack:
+		return fmt.Sprintf("{%d}", decodeInt64(operant))
+	case OperantInt16:
+		return fmt.Sprintf("%d", decodeInt16(operant))
+	case OperantInt32:
+		return fmt.Sprintf("%d", decodeInt32(operant))
+	}
+
+	return ""
+}
+
+// -- Values on the stack -------------------------------------------------------------------------------------------------------
+
+// ValueType is the type of a value on the stack
+type ValueType int
+
+const (
+	TypeByte ValueType = iota
+	TypeInt
+	TypeFloat
+	TypeInt16
+	TypeInt32
+	TypeString // on the stack as the address of the string in memory, an int
+)
+
+// Size returns the number of bytes a value takes on the stack or in memory
+func (t ValueType) Size() int {
+	switch t {
+	case TypeByte:
+		return ByteSize
+	case TypeInt, TypeString:
+		return IntSize
+	case TypeFloat:
+		return FloatSize
+	case TypeInt16:
+		return Int16Size
+	case TypeInt32:
+		return Int32Size
+	}
+
+	return 0
+}
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeByte:
+		return "byte"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeInt16:
+		return "int16"
+	case TypeInt32:
+		return "int32"
+	case TypeString:
+		return "string"
+	}
+
+	return "?"
+}
+
+// -- Instructions --------------------------------------------------------------------------------------------------------------
+
+// Instruction describes a single opcode: how it is written, what it does to the stack and the function executing it
+type Instruction struct {
+	Opcode      Opcode
+	Mnemonic    string
+	Operant     OperantKind
+	Pops        []ValueType // values taken from the stack, topmost first
+	Pushes      []ValueType // values put on the stack, in order
+	Description string
+	Handler     Operation // nil for end
+	Extension   Extension // extension the instruction belongs to, 0 for the base set
+}
+
+// Size returns the number of bytes the instruction takes in memory
+func (in *Instruction) Size() int {
+	return in.Opcode.Size() + in.Operant.Size()
+}
+
+// StackEffect returns the number of bytes the instruction adds to (or removes from, when negative) the stack
+func (in *Instruction) StackEffect() int {
+	effect := 0
+	for _, t := range in.Pops {
+		effect -= t.Size()
+	}
+	for _, t := range in.Pushes {
+		effect += t.Size()
+	}
+
+	return effect
+}
+
+// Format renders the instruction the way the assembler reads it
+func (in *Instruction) Format(operant []byte) string {
+	if in.Operant == OperantNone {
+		return in.Mnemonic
+	}
+
+	return in.Mnemonic + " " + in.Operant.Format(operant)
+}
+
+// stackNotation shows the stack effect Forth-style, topmost value at the right
+func (in *Instruction) stackNotation() string {
+	var before []string
+	for i := len(in.Pops) - 1; i >= 0; i-- {
+		before = append(before, in.Pops[i].String())
+	}
+
+	var after []string
+	for _, t := range in.Pushes {
+		after = append(after, t.String())
+	}
+
+	return strings.TrimSpace(strings.Join(before, " ") + " -- " + strings.Join(after, " "))
+}
+
+// shorthands to keep the table readable
+var (
+	noValues  = []ValueType{}
+	aByte     = []ValueType{TypeByte}
+	anInt     = []ValueType{TypeInt}
+	aFloat    = []ValueType{TypeFloat}
+	twoBytes  = []ValueType{TypeByte, TypeByte}
+	twoInts   = []ValueType{TypeInt, TypeInt}
+	twoFloats = []ValueType{TypeFloat, TypeFloat}
+	intByte   = []ValueType{TypeInt, TypeByte}
+	intFloat  = []ValueType{TypeInt, TypeFloat}
+	byteInt   = []ValueType{TypeByte, TypeInt}
+
+	threeBytes  = []ValueType{TypeByte, TypeByte, TypeByte}
+	threeInts   = []ValueType{TypeInt, TypeInt, TypeInt}
+	threeFloats = []ValueType{TypeFloat, TypeFloat, TypeFloat}
+
+	anInt16   = []ValueType{TypeInt16}
+	twoInt16s = []ValueType{TypeInt16, TypeInt16}
+	intInt16  = []ValueType{TypeInt, TypeInt16}
+	anInt32   = []ValueType{TypeInt32}
+	twoInt32s = []ValueType{TypeInt32, TypeInt32}
+	intInt32  = []ValueType{TypeInt, TypeInt32}
+
+	aString      = []ValueType{TypeString}
+	twoStrings   = []ValueType{TypeString, TypeString}
+	intString    = []ValueType{TypeInt, TypeString}
+	twoIntString = []ValueType{TypeInt, TypeInt, TypeString}
+)
+
+// InstructionSet is the single source of truth for the opcodes: the virtual machine, its tracing, the assembler, the
+// disassembler and the opcode table in the readme are all derived from it.
+var InstructionSet = []Instruction{
+	{0x00, "end", OperantNone, noValues, noValues, "ends the program", nil, ExtensionBase},
+
+	{0x08, "push-byte", OperantByte, noValues, aByte, "pushes a constant byte value on the stack", (*VirtualMachine).operationPushByte, ExtensionBase},
+	{0x09, "push-int", OperantInt, noValues, anInt, "pushes a contant integer value on the stack", (*VirtualMachine).operationPushInt, ExtensionBase},
+	{0x0A, "push-float", OperantFloat, noValues, aFloat, "pushes a constant float value on the stack", (*VirtualMachine).operationPushFloat, ExtensionBase},
+
+	{0x0C, "pop-byte", OperantNone, aByte, noValues, "pops a byte from the stack (and looses it)", (*VirtualMachine).operationPopByte, ExtensionBase},
+	{0x0D, "pop-int", OperantNone, anInt, noValues, "pops an integer from the stack (and looses it)", (*VirtualMachine).operationPopInt, ExtensionBase},
+	{0x0E, "pop-float", OperantNone, aFloat, noValues, "pops a float value from the stack (and looses it)", (*VirtualMachine).operationPopFloat, ExtensionBase},
+
+	{0x10, "get-byte", OperantNone, anInt, aByte, "pops an address from stack, retrieves a byte from this address and push it onto the stack", (*VirtualMachine).operationGetByte, ExtensionBase},
+	{0x11, "get-int", OperantNone, anInt, anInt, "pops an address from stack, retrieves an int from this address and push it onto the stack", (*VirtualMachine).operationGetInt, ExtensionBase},
+	{0x12, "get-float", OperantNone, anInt, aFloat, "pops an address from stack, retrieves a float from this address and push it onto the stack", (*VirtualMachine).operationGetFloat, ExtensionBase},
+
+	{0x18, "put-byte", OperantNone, intByte, noValues, "pops an address from stack, pops a byte from stack and stores it in memory", (*VirtualMachine).operationPutByte, ExtensionBase},
+	{0x19, "put-int", OperantNone, twoInts, noValues, "pops an address from stack, pops an int from stack and stores it in memory", (*VirtualMachine).operationPutInt, ExtensionBase},
+	{0x1A, "put-float", OperantNone, intFloat, noValues, "pops an address from stack, pops a float from stack and stores it in memory", (*VirtualMachine).operationPutFloat, ExtensionBase},
+
+	{0x20, "get-byte", OperantAddress, noValues, aByte, "pushes a byte from memory on the stack", (*VirtualMachine).operationGetByteAddress, ExtensionBase},
+	{0x21, "get-int", OperantAddress, noValues, anInt, "pushes an int from memory on the stack", (*VirtualMachine).operationGetIntAddress, ExtensionBase},
+	{0x22, "get-float", OperantAddress, noValues, aFloat, "pushes a float from memory on the stack", (*VirtualMachine).operationGetFloatAddress, ExtensionBase},
+
+	{0x28, "put-byte", OperantAddress, aByte, noValues, "stores a byte from stack into memory", (*VirtualMachine).operationPutByteAddress, ExtensionBase},
+	{0x29, "put-int", OperantAddress, anInt, noValues, "stores an int from stack into memory", (*VirtualMachine).operationPutIntAddress, ExtensionBase},
+	{0x2A, "put-float", OperantAddress, aFloat, noValues, "stores a float from stack into memory", (*VirtualMachine).operationPutFloatAddress, ExtensionBase},
+
+	{0x30, "get-byte", OperantStack, noValues, aByte, "pushes a byte from an address relative to the stackpointer on top of the stack", (*VirtualMachine).operationGetByteStack, ExtensionBase},
+	{0x31, "get-int", OperantStack, noValues, anInt, "pushes an int from an address relative to the stackpointer on top of the stack", (*VirtualMachine).operationGetIntStack, ExtensionBase},
+	{0x32, "get-float", OperantStack, noValues, aFloat, "pushes a float from an address relative to the stackpointer on top of the stack", (*VirtualMachine).operationGetFloatStack, ExtensionBase},
+
+	{0x38, "put-byte", OperantStack, aByte, noValues, "pops a byte from the stack and stores it in address relative to the stackpointer", (*VirtualMachine).operationPutByteStack, ExtensionBase},
+	{0x39, "put-int", OperantStack, anInt, noValues, "pops an int from the stack and stores it in address relative to the stackpointer", (*VirtualMachine).operationPutIntStack, ExtensionBase},
+	{0x3A, "put-float", OperantStack, aFloat, noValues, "pops a float from the stack and stores it in address relative to the stackpointer", (*VirtualMachine).operationPutFloatStack, ExtensionBase},
+
+	{0x40, "add-byte", OperantNone, twoBytes, aByte, "adds the two topmost bytes on stack", (*VirtualMachine).operationAddByte, ExtensionBase},
+	{0x41, "add-int", OperantNone, twoInts, anInt, "adds the two topmost ints on stack", (*VirtualMachine).operationAddInt, ExtensionBase},
+	{0x42, "add-float", OperantNone, twoFloats, aFloat, "adds the two topmost floats on the stack", (*VirtualMachine).operationAddFloat, ExtensionBase},
+
+	{0x44, "sub-byte", OperantNone, twoBytes, aByte, "subtracts the two topmost bytes on stack", (*VirtualMachine).operationSubByte, ExtensionBase},
+	{0x45, "sub-int", OperantNone, twoInts, anInt, "subtracts the two topmost ints on stack", (*VirtualMachine).operationSubInt, ExtensionBase},
+	{0x46, "sub-float", OperantNone, twoFloats, aFloat, "subtracts the two topmost floats on stack", (*VirtualMachine).operationSubFloat, ExtensionBase},
+
+	{0x48, "mul-byte", OperantNone, twoBytes, aByte, "multiplies the two topmost bytes on stack", (*VirtualMachine).operationMulByte, ExtensionBase},
+	{0x49, "mul-int", OperantNone, twoInts, anInt, "multiplies the two topmost ints on stack", (*VirtualMachine).operationMulInt, ExtensionBase},
+	{0x4A, "mul-float", OperantNone, twoFloats, aFloat, "multiplies the two topmost floats on stack", (*VirtualMachine).operationMulFloat, ExtensionBase},
+
+	{0x4C, "div-byte", OperantNone, twoBytes, aByte, "divides the two topmost bytes on stack", (*VirtualMachine).operationDivByte, ExtensionBase},
+	{0x4D, "div-int", OperantNone, twoInts, anInt, "divides the two topmost ints on stack", (*VirtualMachine).operationDivInt, ExtensionBase},
+	{0x4E, "div-float", OperantNone, twoFloats, aFloat, "divides the two topmost floats on stack", (*VirtualMachine).operationDivFloat, ExtensionBase},
+
+	{0x50, "mod-byte", OperantNone, twoBytes, aByte, "pops 2 bytes, pushes the remainder of the 2nd divided by the topmost, faults on zero", (*VirtualMachine).operationModByte, ExtensionBase},
+	{0x51, "mod-int", OperantNone, twoInts, anInt, "pops 2 ints, pushes the remainder of the 2nd by the topmost, signed like the 2nd, faults on zero", (*VirtualMachine).operationModInt, ExtensionBase},
+	{0x52, "neg-int", OperantNone, anInt, anInt, "pops an int and pushes it negated, overflow of the smallest int depends on the policy", (*VirtualMachine).operationNegInt, ExtensionBase},
+	{0x53, "neg-float", OperantNone, aFloat, aFloat, "pops a float and pushes it negated", (*VirtualMachine).operationNegFloat, ExtensionBase},
+
+	{0x54, "inc-byte", OperantNone, aByte, aByte, "adds one to the topmost byte, FF wraps around to 00", (*VirtualMachine).operationIncByte, ExtensionBase},
+	{0x55, "inc-int", OperantNone, anInt, anInt, "adds one to the topmost int, overflow depends on the policy", (*VirtualMachine).operationIncInt, ExtensionBase},
+	{0x56, "dec-byte", OperantNone, aByte, aByte, "subtracts one from the topmost byte, 00 wraps around to FF", (*VirtualMachine).operationDecByte, ExtensionBase},
+	{0x57, "dec-int", OperantNone, anInt, anInt, "subtracts one from the topmost int, overflow depends on the policy", (*VirtualMachine).operationDecInt, ExtensionBase},
+
+	{0x58, "abs-int", OperantNone, anInt, anInt, "pops an int and pushes its absolute value, overflow of the smallest int depends on the policy", (*VirtualMachine).operationAbsInt, ExtensionBase},
+	{0x59, "abs-float", OperantNone, aFloat, aFloat, "pops a float and pushes its absolute value", (*VirtualMachine).operationAbsFloat, ExtensionBase},
+	{0x5A, "greater-equal-byte", OperantNone, twoBytes, aByte, "pops 2 bytes, pushes FF if the 2nd one is greater or equal, 00 if not", (*VirtualMachine).operationGreaterEqualByte, ExtensionBase},
+	{0x5B, "greater-equal-int", OperantNone, twoInts, aByte, "pops 2 ints, pushes FF if the 2nd one is greater or equal, 00 if not", (*VirtualMachine).operationGreaterEqualInt, ExtensionBase},
+
+	{0x5C, "greater-equal-float", OperantNone, twoFloats, aByte, "pops 2 floats, pushes FF if the 2nd one is greater or equal, 00 if not (or NaN)", (*VirtualMachine).operationGreaterEqualFloat, ExtensionBase},
+	{0x5D, "smaller-equal-byte", OperantNone, twoBytes, aByte, "pops 2 bytes, pushes FF if the 2nd one is smaller or equal, 00 if not", (*VirtualMachine).operationSmallerEqualByte, ExtensionBase},
+	{0x5E, "smaller-equal-int", OperantNone, twoInts, aByte, "pops 2 ints, pushes FF if the 2nd one is smaller or equal, 00 if not", (*VirtualMachine).operationSmallerEqualInt, ExtensionBase},
+	{0x5F, "smaller-equal-float", OperantNone, twoFloats, aByte, "pops 2 floats, pushes FF if the 2nd one is smaller or equal, 00 if not (or NaN)", (*VirtualMachine).operationSmallerEqualFloat, ExtensionBase},
+
+	{0x60, "equal-byte", OperantNone, twoBytes, aByte, "compares the topmost two bytes on stack, pushes byte(FF) if equal and 0 otherwise", (*VirtualMachine).operationEqualByte, ExtensionBase},
+	{0x61, "equal-int", OperantNone, twoInts, aByte, "compares the topmost two ints on stack, pushes byte(FF) if equal and 0 otherwise", (*VirtualMachine).operationEqualInt, ExtensionBase},
+	{0x62, "equal-float", OperantNone, twoFloats, aByte, "compares the topmost two floats on stack, pushes byte(FF) if equal and 0 otherwise", (*VirtualMachine).operationEqualFloat, ExtensionBase},
+
+	{0x64, "unequal-byte", OperantNone, twoBytes, aByte, "compares the topmost two bytes on stack, pushes byte(FF) if unequal and 0 otherwise", (*VirtualMachine).operationUnequalByte, ExtensionBase},
+	{0x65, "unequal-int", OperantNone, twoInts, aByte, "compares the topmost two ints on stack, pushes byte(FF) if unequal and 0 otherwise", (*VirtualMachine).operationUnequalInt, ExtensionBase},
+	{0x66, "unequal-float", OperantNone, twoFloats, aByte, "compares the topmost two floats on stack, pushes byte(FF) if unequal and 0 otherwise", (*VirtualMachine).operationUnequalFloat, ExtensionBase},
+
+	{0x68, "greater-byte", OperantNone, twoBytes, aByte, "compares the topmost two bytes on stack, pushes byte(FF) if the bottom one is greater", (*VirtualMachine).operationGreaterByte, ExtensionBase},
+	{0x69, "greater-int", OperantNone, twoInts, aByte, "compares the topmost two ints on stack, pushes byte(FF) if the bottom one is greater", (*VirtualMachine).operationGreaterInt, ExtensionBase},
+	{0x6A, "greater-float", OperantNone, twoFloats, aByte, "compares the topmost two floats on stack, pushes byte(FF) if the bottom one is greater", (*VirtualMachine).operationGreaterFloat, ExtensionBase},
+
+	{0x6C, "smaller-byte", OperantNone, twoBytes, aByte, "compares the topmost two bytes on stack, pushes byte(FF) if the bottom one is smaller", (*VirtualMachine).operationSmallerByte, ExtensionBase},
+	{0x6D, "smaller-int", OperantNone, twoInts, aByte, "compares the topmost two ints on stack, pushes byte(FF) if the bottom one is smaller", (*VirtualMachine).operationSmallerInt, ExtensionBase},
+	{0x6E, "smaller-float", OperantNone, twoFloats, aByte, "compares the topmost two floats on stack, pushes byte(FF) if the bottom one is smaller", (*VirtualMachine).operationSmallerFloat, ExtensionBase},
+
+	{0x70, "and-byte", OperantNone, twoBytes, aByte, "takes the two topmost bytes from stack and pushes a bit-wise AND", (*VirtualMachine).operationAndByte, ExtensionBase},
+	{0x71, "or-byte", OperantNone, twoBytes, aByte, "takes the two topmost bytes from stack and pushes a bit-wise OR", (*VirtualMachine).operationOrByte, ExtensionBase},
+	{0x72, "not-byte", OperantNone, aByte, aByte, "takes the topmost byte from stack and pushes a bit-wise NOT", (*VirtualMachine).operationNotByte, ExtensionBase},
+	{0x73, "xor-byte", OperantNone, twoBytes, aByte, "takes the two topmost bytes from stack and pushes a bit-wise XOR", (*VirtualMachine).operationXorByte, ExtensionBase},
+
+	{0x74, "shl-byte", OperantNone, twoBytes, aByte, "pops a count and a byte, pushes the byte shifted left, 00 for counts from 8", (*VirtualMachine).operationShlByte, ExtensionBase},
+	{0x75, "shl-int", OperantNone, byteInt, anInt, "pops a byte count and an int, pushes the int shifted left, 0 for counts from 64", (*VirtualMachine).operationShlInt, ExtensionBase},
+	{0x76, "shr-byte", OperantNone, twoBytes, aByte, "pops a count and a byte, pushes the byte shifted right filling with zeroes, 00 for counts from 8", (*VirtualMachine).operationShrByte, ExtensionBase},
+	{0x77, "shr-int", OperantNone, byteInt, anInt, "pops a byte count and an int, pushes the int shifted right filling with zeroes, 0 for counts from 64", (*VirtualMachine).operationShrInt, ExtensionBase},
+
+	{0x78, "sar-byte", OperantNone, twoBytes, aByte, "pops a count and a byte, pushes the byte shifted right copying the sign bit", (*VirtualMachine).operationSarByte, ExtensionBase},
+	{0x79, "sar-int", OperantNone, byteInt, anInt, "pops a byte count and an int, pushes the int shifted right copying the sign bit", (*VirtualMachine).operationSarInt, ExtensionBase},
+	{0x7A, "rotl-int", OperantNone, byteInt, anInt, "pops a byte count and an int, pushes the int rotated left by the count modulo 64", (*VirtualMachine).operationRotlInt, ExtensionBits},
+	{0x7B, "rotr-int", OperantNone, byteInt, anInt, "pops a byte count and an int, pushes the int rotated right by the count modulo 64", (*VirtualMachine).operationRotrInt, ExtensionBits},
+
+	{0x7C, "and-int", OperantNone, twoInts, anInt, "takes the two topmost ints from stack and pushes a bit-wise AND", (*VirtualMachine).operationAndInt, ExtensionBits},
+	{0x7D, "or-int", OperantNone, twoInts, anInt, "takes the two topmost ints from stack and pushes a bit-wise OR", (*VirtualMachine).operationOrInt, ExtensionBits},
+	{0x7E, "not-int", OperantNone, anInt, anInt, "takes the topmost int from stack and pushes a bit-wise NOT", (*VirtualMachine).operationNotInt, ExtensionBits},
+	{0x7F, "xor-int", OperantNone, twoInts, anInt, "takes the two topmost ints from stack and pushes a bit-wise XOR", (*VirtualMachine).operationXorInt, ExtensionBits},
+
+	{0x80, "byte-to-int", OperantNone, aByte, anInt, "converts a byte to an int", (*VirtualMachine).operationByteToInt, ExtensionBase},
+	{0x81, "int-to-byte", OperantNone, anInt, aByte, "converts an int to a byte, keeping the lower 8 bits", (*VirtualMachine).operationIntToByte, ExtensionBase},
+	{0x82, "int-to-byte-sat", OperantNone, anInt, aByte, "converts an int to a byte, clamping it to 0..255", (*VirtualMachine).operationIntToByteSaturate, ExtensionBase},
+	{0x83, "byte-to-float", OperantNone, aByte, aFloat, "converts a byte to a float", (*VirtualMachine).operationByteToFloat, ExtensionBase},
+
+	{0x84, "int-to-float", OperantNone, anInt, aFloat, "converts an int to the nearest float", (*VirtualMachine).operationIntToFloat, ExtensionBase},
+	{0x85, "float-to-int", OperantNone, aFloat, anInt, "converts a float to an int, truncating towards zero, clamped to the range of an int, NaN is 0", (*VirtualMachine).operationFloatToInt, ExtensionBase},
+	{0x86, "float-to-int-floor", OperantNone, aFloat, anInt, "as float-to-int, rounding down", (*VirtualMachine).operationFloatToIntFloor, ExtensionBase},
+	{0x87, "float-to-int-round", OperantNone, aFloat, anInt, "as float-to-int, rounding to the nearest int, halves away from zero", (*VirtualMachine).operationFloatToIntRound, ExtensionBase},
+
+	{0x88, "float-to-int-trap", OperantNone, aFloat, anInt, "as float-to-int, but NaN and values out of range raise a fault", (*VirtualMachine).operationFloatToIntTrap, ExtensionBase},
+
+	{0x90, "dup-byte", OperantNone, aByte, twoBytes, "pushes a copy of the topmost byte", (*VirtualMachine).operationDupByte, ExtensionBase},
+	{0x91, "dup-int", OperantNone, anInt, twoInts, "pushes a copy of the topmost int", (*VirtualMachine).operationDupInt, ExtensionBase},
+	{0x92, "dup-float", OperantNone, aFloat, twoFloats, "pushes a copy of the topmost float", (*VirtualMachine).operationDupFloat, ExtensionBase},
+	{0x93, "drop-n", OperantInt, noValues, noValues, "takes a number of bytes as operant and drops them from the stack, whatever their types", (*VirtualMachine).operationDropN, ExtensionBase},
+
+	{0x94, "swap-byte", OperantNone, twoBytes, twoBytes, "exchanges the two topmost bytes", (*VirtualMachine).operationSwapByte, ExtensionBase},
+	{0x95, "swap-int", OperantNone, twoInts, twoInts, "exchanges the two topmost ints", (*VirtualMachine).operationSwapInt, ExtensionBase},
+	{0x96, "swap-float", OperantNone, twoFloats, twoFloats, "exchanges the two topmost floats", (*VirtualMachine).operationSwapFloat, ExtensionBase},
+	{0x97, "swap-byte-int", OperantNone, byteInt, byteInt, "exchanges the byte on top of the stack with the int below it", (*VirtualMachine).operationSwapByteInt, ExtensionBase},
+
+	{0x98, "over-byte", OperantNone, twoBytes, threeBytes, "pushes a copy of the byte below the topmost one", (*VirtualMachine).operationOverByte, ExtensionBase},
+	{0x99, "over-int", OperantNone, twoInts, threeInts, "pushes a copy of the int below the topmost one", (*VirtualMachine).operationOverInt, ExtensionBase},
+	{0x9A, "over-float", OperantNone, twoFloats, threeFloats, "pushes a copy of the float below the topmost one", (*VirtualMachine).operationOverFloat, ExtensionBase},
+	{0x9B, "swap-int-byte", OperantNone, intByte, intByte, "exchanges the int on top of the stack with the byte below it", (*VirtualMachine).operationSwapIntByte, ExtensionBase},
+
+	{0x9C, "rot-byte", OperantNone, threeBytes, threeBytes, "moves the third byte from the top to the top", (*VirtualMachine).operationRotByte, ExtensionBase},
+	{0x9D, "rot-int", OperantNone, threeInts, threeInts, "moves the third int from the top to the top", (*VirtualMachine).operationRotInt, ExtensionBase},
+	{0x9E, "rot-float", OperantNone, threeFloats, threeFloats, "moves the third float from the top to the top", (*VirtualMachine).operationRotFloat, ExtensionBase},
+
+	{0xA0, "sqrt-float", OperantNone, aFloat, aFloat, "pops a float, pushes its square root, NaN for negative numbers", (*VirtualMachine).operationSqrtFloat, ExtensionMath},
+	{0xA1, "exp-float", OperantNone, aFloat, aFloat, "pops a float, pushes e to the power of it", (*VirtualMachine).operationExpFloat, ExtensionMath},
+	{0xA2, "log-float", OperantNone, aFloat, aFloat, "pops a float, pushes its natural logarithm, -Inf for 0 and NaN for negative numbers", (*VirtualMachine).operationLogFloat, ExtensionMath},
+	{0xA3, "pow-float", OperantNone, twoFloats, aFloat, "pops an exponent and a base, pushes the base to the power of the exponent", (*VirtualMachine).operationPowFloat, ExtensionMath},
+
+	{0xA4, "sin-float", OperantNone, aFloat, aFloat, "pops an angle in radians, pushes its sine", (*VirtualMachine).operationSinFloat, ExtensionMath},
+	{0xA5, "cos-float", OperantNone, aFloat, aFloat, "pops an angle in radians, pushes its cosine", (*VirtualMachine).operationCosFloat, ExtensionMath},
+	{0xA6, "tan-float", OperantNone, aFloat, aFloat, "pops an angle in radians, pushes its tangent", (*VirtualMachine).operationTanFloat, ExtensionMath},
+	{0xA7, "atan2-float", OperantNone, twoFloats, aFloat, "pops x and y, pushes the angle of the point (x, y) in radians, between -Pi and Pi", (*VirtualMachine).operationAtan2Float, ExtensionMath},
+
+	{0xA8, "floor-float", OperantNone, aFloat, aFloat, "pops a float, pushes it rounded down to an integer value", (*VirtualMachine).operationFloorFloat, ExtensionMath},
+	{0xA9, "ceil-float", OperantNone, aFloat, aFloat, "pops a float, pushes it rounded up to an integer value", (*VirtualMachine).operationCeilFloat, ExtensionMath},
+	{0xAA, "trunc-float", OperantNone, aFloat, aFloat, "pops a float, pushes its integer part", (*VirtualMachine).operationTruncFloat, ExtensionMath},
+	{0xAB, "round-float", OperantNone, aFloat, aFloat, "pops a float, pushes the nearest integer value, halves away from zero", (*VirtualMachine).operationRoundFloat, ExtensionMath},
+
+	{0xAC, "min-float", OperantNone, twoFloats, aFloat, "pops 2 floats, pushes the smaller one, NaN if either is NaN", (*VirtualMachine).operationMinFloat, ExtensionMath},
+	{0xAD, "max-float", OperantNone, twoFloats, aFloat, "pops 2 floats, pushes the greater one, NaN if either is NaN", (*VirtualMachine).operationMaxFloat, ExtensionMath},
+	{0xAE, "isnan-float", OperantNone, aFloat, aByte, "pops a float, pushes FF if it is NaN, 00 if not", (*VirtualMachine).operationIsNaNFloat, ExtensionMath},
+	{0xAF, "isinf-float", OperantNone, aFloat, aByte, "pops a float, pushes FF if it is +Inf or -Inf, 00 if not", (*VirtualMachine).operationIsInfFloat, ExtensionMath},
+
+	{0xB0, "push-string", OperantAddress, noValues, aString, "pushes the string at address nn, as written by .string", (*VirtualMachine).operationPushString, ExtensionStrings},
+	{0xB1, "length-string", OperantNone, aString, anInt, "pops a string, pushes its length in bytes", (*VirtualMachine).operationLengthString, ExtensionStrings},
+	{0xB2, "concat-string", OperantNone, twoStrings, aString, "pops 2 strings, pushes a new string with the topmost one appended to the 2nd", (*VirtualMachine).operationConcatString, ExtensionStrings},
+	{0xB3, "slice-string", OperantNone, twoIntString, aString, "pops an end, a start and a string, pushes a new string of the bytes from start up to end", (*VirtualMachine).operationSliceString, ExtensionStrings},
+
+	{0xB4, "compare-string", OperantNone, twoStrings, anInt, "pops 2 strings, pushes -1, 0 or 1 when the 2nd one sorts before, equal to or after the topmost", (*VirtualMachine).operationCompareString, ExtensionStrings},
+	{0xB5, "index-string", OperantNone, twoStrings, anInt, "pops a string to look for and a string, pushes the byte index where it is first found, or -1", (*VirtualMachine).operationIndexString, ExtensionStrings},
+	{0xB6, "byte-at-string", OperantNone, intString, aByte, "pops an index and a string, pushes the byte at the index", (*VirtualMachine).operationByteAtString, ExtensionStrings},
+
+	{0xB8, "int-to-string", OperantNone, anInt, aString, "pops an int, pushes it as a new decimal string", (*VirtualMachine).operationIntToString, ExtensionStrings},
+	{0xB9, "float-to-string", OperantNone, aFloat, aString, "pops a float, pushes it as a new string in the shortest form that reads back the same", (*VirtualMachine).operationFloatToString, ExtensionStrings},
+	{0xBA, "string-to-int", OperantNone, aString, anInt, "pops a string, pushes the decimal int it holds, faults when it holds none or it doesn't fit", (*VirtualMachine).operationStringToInt, ExtensionStrings},
+	{0xBB, "string-to-float", OperantNone, aString, aFloat, "pops a string, pushes the float it holds, faults when it holds none", (*VirtualMachine).operationStringToFloat, ExtensionStrings},
+
+	{0xC0, "alloc-n", OperantInt, noValues, anInt, "takes a number of bytes as operant, allocates them on the heap and pushes their address", (*VirtualMachine).operationAllocN, ExtensionHeap},
+	{0xC1, "alloc", OperantNone, anInt, anInt, "pops a number of bytes, allocates them on the heap and pushes their address", (*VirtualMachine).operationAlloc, ExtensionHeap},
+	{0xC2, "free", OperantNone, anInt, noValues, "pops the address of a block on the heap and frees it", (*VirtualMachine).operationFree, ExtensionHeap},
+	{0xC3, "realloc", OperantNone, twoInts, anInt, "pops a number of bytes and the address of a block, resizes the block and pushes its address", (*VirtualMachine).operationRealloc, ExtensionHeap},
+	{0xC4, "gc", OperantNone, noValues, noValues, "collects the garbage on the heap: frees the blocks no root refers to, directly or through others", (*VirtualMachine).operationGC, ExtensionHeap},
+
+	{0xD0, "print-byte", OperantNone, aByte, noValues, "pops a byte and writes it to the output as a decimal number", (*VirtualMachine).operationPrintByte, ExtensionConsole},
+	{0xD1, "print-int", OperantNone, anInt, noValues, "pops an int and writes it to the output as a decimal number", (*VirtualMachine).operationPrintInt, ExtensionConsole},
+	{0xD2, "print-float", OperantNone, aFloat, noValues, "pops a float and writes it to the output in the shortest form that reads back the same", (*VirtualMachine).operationPrintFloat, ExtensionConsole},
+	{0xD3, "print-char", OperantNone, aByte, noValues, "pops a byte and writes it to the output as it is", (*VirtualMachine).operationPrintChar, ExtensionConsole},
+
+	{0xD4, "print-string", OperantNone, aString, noValues, "pops a string and writes its bytes to the output", (*VirtualMachine).operationPrintString, ExtensionConsole},
+
+	{0xD8, "read-char", OperantNone, noValues, anInt, "reads a byte from the input and pushes it as an int, -1 at the end of the input", (*VirtualMachine).operationReadChar, ExtensionConsole},
+	{0xD9, "read-int", OperantNone, noValues, anInt, "skips white space on the input, reads a decimal int and pushes it", (*VirtualMachine).operationReadInt, ExtensionConsole},
+	{0xDA, "read-float", OperantNone, noValues, aFloat, "skips white space on the input, reads a float and pushes it", (*VirtualMachine).operationReadFloat, ExtensionConsole},
+
+	{0xDC, "read-line", OperantNone, noValues, aString, "reads a line from the input and pushes it as a new string without the newline, -1 at the end", (*VirtualMachine).operationReadLine, ExtensionConsole},
+
+	{0xE0, "ret", OperantNone, anInt, noValues, "pop an address from stack and jump there", (*VirtualMachine).operationRet, ExtensionBase},
+	{0xE1, "jmp", OperantAddress, noValues, noValues, "takes an address operant and jumps there", (*VirtualMachine).operationJmp, ExtensionBase},
+	{0xE2, "reti", OperantNone, anInt, noValues, "pop an address from stack, jumps there and enables interrupts, to return from an interrupt handler", (*VirtualMachine).operationReti, ExtensionInterrupts},
+
+	{0xE4, "jmpz-byte", OperantNone, intByte, noValues, "pops an address and a byte from stack, jumps to the address if the byte == 0", (*VirtualMachine).operationJmpzByte, ExtensionBase},
+	{0xE5, "jmpz-int", OperantNone, twoInts, noValues, "pops an address and an int from stack, jumps to the address if the int == 0", (*VirtualMachine).operationJmpzInt, ExtensionBase},
+	{0xE6, "jmpz-float", OperantNone, intFloat, noValues, "pops an address and a float from stack, jumps to the address if the float == 0.0", (*VirtualMachine).operationJmpzFloat, ExtensionBase},
+
+	{0xE8, "jmpz-byte", OperantAddress, aByte, noValues, "takes an address as opperant and pops a byte from stack, jumps to the address if the byte == 0", (*VirtualMachine).operationJmpzByteAddress, ExtensionBase},
+	{0xE9, "jmpz-int", OperantAddress, anInt, noValues, "takes an address as opperant and pops an int from stack, jumps to the address if the int == 0", (*VirtualMachine).operationJmpzIntAddress, ExtensionBase},
+	{0xEA, "jmpz-float", OperantAddress, aFloat, noValues, "takes an address as opperant and pops a float from stack, jumps to the address if the float == 0.0", (*VirtualMachine).operationJmpzFloatAddress, ExtensionBase},
+
+	{0xEC, "jmpnz-byte", OperantNone, intByte, noValues, "pops an address and a byte from stack, jumps to the address if the byte != 0", (*VirtualMachine).operationJmpnzByte, ExtensionBase},
+	{0xED, "jmpnz-int", OperantNone, twoInts, noValues, "pops an address and an int from stack, jumps to the address if the int != 0", (*VirtualMachine).operationJmpnzInt, ExtensionBase},
+	{0xEE, "jmpnz-float", OperantNone, intFloat, noValues, "pops an address and a float from stack, jumps to the address if the float != 0.0", (*VirtualMachine).operationJmpnzFloat, ExtensionBase},
+
+	{0xF0, "jmpnz-byte", OperantAddress, aByte, noValues, "takes an address as opperant and pops a byte from stack, jumps to the address if the byte != 0", (*VirtualMachine).operationJmpnzByteAddress, ExtensionBase},
+	{0xF1, "jmpnz-int", OperantAddress, anInt, noValues, "takes an address as opperant and pops an int from stack, jumps to the address if the int != 0", (*VirtualMachine).operationJmpnzIntAddress, ExtensionBase},
+	{0xF2, "jmpnz-float", OperantAddress, aFloat, noValues, "takes an address as opperant and pops a float from stack, jumps to the address if the float != 0.0", (*VirtualMachine).operationJmpnzFloatAddress, ExtensionBase},
+
+	{0xF8, "call", OperantNone, anInt, anInt, "pop an address from stack, pushes current pointer+1 and jumps to the address", (*VirtualMachine).operationCall, ExtensionBase},
+	{0xF9, "call", OperantAddress, noValues, anInt, "takes an address operant, pushes current pointer+1 and jumps to the address", (*VirtualMachine).operationCallAddress, ExtensionBase},
+	{0xFA, "syscall", OperantInt, noValues, noValues, "calls the host function registered under nn, it pops its arguments and pushes its results", (*VirtualMachine).operationSyscall, ExtensionHost},
+	{0xFC, "ei", OperantNone, noValues, noValues, "enables interrupts, pending ones are taken before the next instruction", (*VirtualMachine).operationEi, ExtensionInterrupts},
+	{0xFD, "di", OperantNone, noValues, noValues, "disables interrupts, raised ones stay pending", (*VirtualMachine).operationDi, ExtensionInterrupts},
+
+	// Extended page
+
+	{0xFF08, "push-int16", OperantInt16, noValues, anInt16, "pushes a constant int16 value on the stack", (*VirtualMachine).operationPushInt16, ExtensionSizedInts},
+	{0xFF09, "push-int32", OperantInt32, noValues, anInt32, "pushes a constant int32 value on the stack", (*VirtualMachine).operationPushInt32, ExtensionSizedInts},
+
+	{0xFF0C, "pop-int16", OperantNone, anInt16, noValues, "pops an int16 from the stack (and looses it)", (*VirtualMachine).operationPopInt16, ExtensionSizedInts},
+	{0xFF0D, "pop-int32", OperantNone, anInt32, noValues, "pops an int32 from the stack (and looses it)", (*VirtualMachine).operationPopInt32, ExtensionSizedInts},
+
+	{0xFF10, "get-int16", OperantNone, anInt, anInt16, "pops an address from stack, retrieves an int16 from this address and push it onto the stack", (*VirtualMachine).operationGetInt16, ExtensionSizedInts},
+	{0xFF11, "get-int32", OperantNone, anInt, anInt32, "pops an address from stack, retrieves an int32 from this address and push it onto the stack", (*VirtualMachine).operationGetInt32, ExtensionSizedInts},
+
+	{0xFF18, "put-int16", OperantNone, intInt16, noValues, "pops an address from stack, pops an int16 from stack and stores it in memory", (*VirtualMachine).operationPutInt16, ExtensionSizedInts},
+	{0xFF19, "put-int32", OperantNone, intInt32, noValues, "pops an address from stack, pops an int32 from stack and stores it in memory", (*VirtualMachine).operationPutInt32, ExtensionSizedInts},
+
+	{0xFF20, "get-int16", OperantAddress, noValues, anInt16, "pushes an int16 from memory on the stack", (*VirtualMachine).operationGetInt16Address, ExtensionSizedInts},
+	{0xFF21, "get-int32", OperantAddress, noValues, anInt32, "pushes an int32 from memory on the stack", (*VirtualMachine).operationGetInt32Address, ExtensionSizedInts},
+
+	{0xFF28, "put-int16", OperantAddress, anInt16, noValues, "stores an int16 from stack into memory", (*VirtualMachine).operationPutInt16Address, ExtensionSizedInts},
+	{0xFF29, "put-int32", OperantAddress, anInt32, noValues, "stores an int32 from stack into memory", (*VirtualMachine).operationPutInt32Address, ExtensionSizedInts},
+
+	{0xFF30, "get-int16", OperantStack, noValues, anInt16, "pushes an int16 from an address relative to the stackpointer on top of the stack", (*VirtualMachine).operationGetInt16Stack, ExtensionSizedInts},
+	{0xFF31, "get-int32", OperantStack, noValues, anInt32, "pushes an int32 from an address relative to the stackpointer on top of the stack", (*VirtualMachine).operationGetInt32Stack, ExtensionSizedInts},
+
+	{0xFF38, "put-int16", OperantStack, anInt16, noValues, "pops an int16 from the stack and stores it in address relative to the stackpointer", (*VirtualMachine).operationPutInt16Stack, ExtensionSizedInts},
+	{0xFF39, "put-int32", OperantStack, anInt32, noValues, "pops an int32 from the stack and stores it in address relative to the stackpointer", (*VirtualMachine).operationPutInt32Stack, ExtensionSizedInts},
+
+	{0xFF40, "add-int16", OperantNone, twoInt16s, anInt16, "adds the two topmost int16s on stack", (*VirtualMachine).operationAddInt16, ExtensionSizedInts},
+	{0xFF41, "add-int32", OperantNone, twoInt32s, anInt32, "adds the two topmost int32s on stack", (*VirtualMachine).operationAddInt32, ExtensionSizedInts},
+
+	{0xFF44, "sub-int16", OperantNone, twoInt16s, anInt16, "subtracts the two topmost int16s on stack", (*VirtualMachine).operationSubInt16, ExtensionSizedInts},
+	{0xFF45, "sub-int32", OperantNone, twoInt32s, anInt32, "subtracts the two topmost int32s on stack", (*VirtualMachine).operationSubInt32, ExtensionSizedInts},
+
+	{0xFF48, "mul-int16", OperantNone, twoInt16s, anInt16, "multiplies the two topmost int16s on stack", (*VirtualMachine).operationMulInt16, ExtensionSizedInts},
+	{0xFF49, "mul-int32", OperantNone, twoInt32s, anInt32, "multiplies the two topmost int32s on stack", (*VirtualMachine).operationMulInt32, ExtensionSizedInts},
+
+	{0xFF4C, "div-int16", OperantNone, twoInt16s, anInt16, "divides the two topmost int16s on stack", (*VirtualMachine).operationDivInt16, ExtensionSizedInts},
+	{0xFF4D, "div-int32", OperantNone, twoInt32s, anInt32, "divides the two topmost int32s on stack", (*VirtualMachine).operationDivInt32, ExtensionSizedInts},
+	{0xFF4E, "div-uint16", OperantNone, twoInt16s, anInt16, "divides the two topmost int16s on stack as unsigned values", (*VirtualMachine).operationDivUint16, ExtensionSizedInts},
+	{0xFF4F, "div-uint32", OperantNone, twoInt32s, anInt32, "divides the two topmost int32s on stack as unsigned values", (*VirtualMachine).operationDivUint32, ExtensionSizedInts},
+
+	{0xFF60, "equal-int16", OperantNone, twoInt16s, aByte, "compares the topmost two int16s on stack, pushes byte(FF) if equal and 0 otherwise", (*VirtualMachine).operationEqualInt16, ExtensionSizedInts},
+	{0xFF61, "equal-int32", OperantNone, twoInt32s, aByte, "compares the topmost two int32s on stack, pushes byte(FF) if equal and 0 otherwise", (*VirtualMachine).operationEqualInt32, ExtensionSizedInts},
+
+	{0xFF64, "unequal-int16", OperantNone, twoInt16s, aByte, "compares the topmost two int16s on stack, pushes byte(FF) if unequal and 0 otherwise", (*VirtualMachine).operationUnequalInt16, ExtensionSizedInts},
+	{0xFF65, "unequal-int32", OperantNone, twoInt32s, aByte, "compares the topmost two int32s on stack, pushes byte(FF) if unequal and 0 otherwise", (*VirtualMachine).operationUnequalInt32, ExtensionSizedInts},
+
+	{0xFF68, "greater-int16", OperantNone, twoInt16s, aByte, "compares the topmost two int16s on stack, pushes byte(FF) if the bottom one is greater", (*VirtualMachine).operationGreaterInt16, ExtensionSizedInts},
+	{0xFF69, "greater-int32", OperantNone, twoInt32s, aByte, "compares the topmost two int32s on stack, pushes byte(FF) if the bottom one is greater", (*VirtualMachine).operationGreaterInt32, ExtensionSizedInts},
+	{0xFF6A, "greater-uint16", OperantNone, twoInt16s, aByte, "as greater-int16, comparing unsigned values", (*VirtualMachine).operationGreaterUint16, ExtensionSizedInts},
+	{0xFF6B, "greater-uint32", OperantNone, twoInt32s, aByte, "as greater-int32, comparing unsigned values", (*VirtualMachine).operationGreaterUint32, ExtensionSizedInts},
+
+	{0xFF6C, "smaller-int16", OperantNone, twoInt16s, aByte, "compares the topmost two int16s on stack, pushes byte(FF) if the bottom one is smaller", (*VirtualMachine).operationSmallerInt16, ExtensionSizedInts},
+	{0xFF6D, "smaller-int32", OperantNone, twoInt32s, aByte, "compares the topmost two int32s on stack, pushes byte(FF) if the bottom one is smaller", (*VirtualMachine).operationSmallerInt32, ExtensionSizedInts},
+	{0xFF6E, "smaller-uint16", OperantNone, twoInt16s, aByte, "as smaller-int16, comparing unsigned values", (*VirtualMachine).operationSmallerUint16, ExtensionSizedInts},
+	{0xFF6F, "smaller-uint32", OperantNone, twoInt32s, aByte, "as smaller-int32, comparing unsigned values", (*VirtualMachine).operationSmallerUint32, ExtensionSizedInts},
+
+	{0xFF70, "popcount-int", OperantNone, anInt, aByte, "pops an int, pushes the number of bits set as a byte", (*VirtualMachine).operationPopcountInt, ExtensionBits},
+	{0xFF71, "clz-int", OperantNone, anInt, aByte, "pops an int, pushes the number of leading zero bits as a byte, 64 for 0", (*VirtualMachine).operationClzInt, ExtensionBits},
+	{0xFF72, "ctz-int", OperantNone, anInt, aByte, "pops an int, pushes the number of trailing zero bits as a byte, 64 for 0", (*VirtualMachine).operationCtzInt, ExtensionBits},
+
+	{0xFF74, "bit-test-int", OperantNone, byteInt, aByte, "pops a bit number and an int, pushes FF if the bit is set, 00 if not or beyond the int", (*VirtualMachine).operationBitTestInt, ExtensionBits},
+	{0xFF75, "bit-set-int", OperantNone, byteInt, anInt, "pops a bit number and an int, pushes the int with the bit set", (*VirtualMachine).operationBitSetInt, ExtensionBits},
+	{0xFF76, "bit-clear-int", OperantNone, byteInt, anInt, "pops a bit number and an int, pushes the int with the bit cleared", (*VirtualMachine).operationBitClearInt, ExtensionBits},
+
+	{0xFF80, "int16-to-int", OperantNone, anInt16, anInt, "converts an int16 to an int, extending the sign", (*VirtualMachine).operationInt16ToInt, ExtensionSizedInts},
+	{0xFF81, "int32-to-int", OperantNone, anInt32, anInt, "converts an int32 to an int, extending the sign", (*VirtualMachine).operationInt32ToInt, ExtensionSizedInts},
+	{0xFF82, "uint16-to-int", OperantNone, anInt16, anInt, "converts an int16 to an int, extending with zeroes", (*VirtualMachine).operationUint16ToInt, ExtensionSizedInts},
+	{0xFF83, "uint32-to-int", OperantNone, anInt32, anInt, "converts an int32 to an int, extending with zeroes", (*VirtualMachine).operationUint32ToInt, ExtensionSizedInts},
+
+	{0xFF84, "int-to-int16", OperantNone, anInt, anInt16, "converts an int to an int16, keeping the lower 16 bits", (*VirtualMachine).operationIntToInt16, ExtensionSizedInts},
+	{0xFF85, "int-to-int32", OperantNone, anInt, anInt32, "converts an int to an int32, keeping the lower 32 bits", (*VirtualMachine).operationIntToInt32, ExtensionSizedInts},
+	{0xFF86, "int8-to-int", OperantNone, aByte, anInt, "converts a byte to an int, extending the sign", (*VirtualMachine).operationInt8ToInt, ExtensionSizedInts},
+
+	{0xFF88, "int8-to-int16", OperantNone, aByte, anInt16, "converts a byte to an int16, extending the sign", (*VirtualMachine).operationInt8ToInt16, ExtensionSizedInts},
+	{0xFF89, "int8-to-int32", OperantNone, aByte, anInt32, "converts a byte to an int32, extending the sign", (*VirtualMachine).operationInt8ToInt32, ExtensionSizedInts},
+	{0xFF8A, "byte-to-int16", OperantNone, aByte, anInt16, "converts a byte to an int16, extending with zeroes", (*VirtualMachine).operationByteToInt16, ExtensionSizedInts},
+	{0xFF8B, "byte-to-int32", OperantNone, aByte, anInt32, "converts a byte to an int32, extending with zeroes", (*VirtualMachine).operationByteToInt32, ExtensionSizedInts},
+
+	{0xFF8C, "int16-to-byte", OperantNone, anInt16, aByte, "converts an int16 to a byte, keeping the lower 8 bits", (*VirtualMachine).operationInt16ToByte, ExtensionSizedInts},
+	{0xFF8D, "int32-to-byte", OperantNone, anInt32, aByte, "converts an int32 to a byte, keeping the lower 8 bits", (*VirtualMachine).operationInt32ToByte, ExtensionSizedInts},
+
+	{0xFF90, "int16-to-int32", OperantNone, anInt16, anInt32, "converts an int16 to an int32, extending the sign", (*VirtualMachine).operationInt16ToInt32, ExtensionSizedInts},
+	{0xFF92, "uint16-to-int32", OperantNone, anInt16, anInt32, "converts an int16 to an int32, extending with zeroes", (*VirtualMachine).operationUint16ToInt32, ExtensionSizedInts},
+
+	{0xFF94, "int32-to-int16", OperantNone, anInt32, anInt16, "converts an int32 to an int16, keeping the lower 16 bits", (*VirtualMachine).operationInt32ToInt16, ExtensionSizedInts},
+}
+
+// opcodeIndex finds the instructions by opcode
+var opcodeIndex = func() map[Opcode]*Instruction {
+	index := make(map[Opcode]*Instruction, len(InstructionSet))
+	for i := range InstructionSet {
+		index[InstructionSet[i].Opcode] = &InstructionSet[i]
+	}
+	return index
+}()
+
+// LookupOpcode returns the instruction for an opcode, nil if the opcode is unknown
+func LookupOpcode(opcode Opcode) *Instruction {
+	return opcodeIndex[opcode]
+}
+
+// -- Documentation -------------------------------------------------------------------------------------------------------------
+
+// WriteInstructionTable writes the instruction set as a markdown table, leaving an empty row between sections
+func WriteInstructionTable(w io.Writer) error {
+	rows := []string{
+		"| Opcode | Mnemonic             | Stack                                    | Description                                                                                          |",
+		"|-------:|:---------------------|:-----------------------------------------|:-----------------------------------------------------------------------------------------------------|",
+	}
+	empty := fmt.Sprintf("| %6s | %-20s | %-40s | %-100s |", "", "", "", "")
+
+	for i := range InstructionSet {
+		in := &InstructionSet[i]
+		if i > 0 && int(in.Opcode) > int(InstructionSet[i-1].Opcode)+1 {
+			rows = append(rows, empty)
+		}
+
+		mnemonic := in.Mnemonic
+		switch in.Operant {
+		case OperantByte, OperantInt, OperantFloat, OperantInt16, OperantInt32:
+			mnemonic = fmt.Sprintf("%-11s nn", in.Mnemonic)
+		case OperantAddress:
+			mnemonic = fmt.Sprintf("%-11s (nn)", in.Mnemonic)
+		case OperantStack:
+			mnemonic = fmt.Sprintf("%-11s {nn}", in.Mnemonic)
+		}
+
+		rows = append(rows, fmt.Sprintf("| %-6s | %-20s | %-40s | %-100s |", fmt.Sprintf("0x%02X", uint16(in.Opcode)), mnemonic, in.stackNotation(), in.Description))
+	}
+
+	for _, row := range rows {
+		_, err := fmt.Fprintln(w, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package virtualmachine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestInstructionSetUnique(t *testing.T) {
+	opcodes := make(map[Opcode]bool)
+	mnemonics := make(map[string]bool)
+
+	for _, in := range InstructionSet {
+		if opcodes[in.Opcode] {
+			t.Errorf("opcode %02X defined twice", in.Opcode)
+		}
+		opcodes[in.Opcode] = true
+
+		key := in.Mnemonic + " " + in.Operant.Format(make([]byte, in.Operant.Size()))
+		if mnemonics[key] {
+			t.Errorf("%s defined twice", key)
+		}
+		mnemonics[key] = true
+
+		if in.Handler == nil && in.Opcode != 0x00 {
+			t.Errorf("%s has no handler", in.Mnemonic)
+		}
+	}
+}
+
+func TestInstructionSetLookup(t *testing.T) {
+	in := LookupOpcode(0x29)
+	if in == nil || in.Mnemonic != "put-int" || in.Operant != OperantAddress {
+		t.Fatalf("Expected put-int (nn)")
+	}
+	if in.Size() != 1+TypeInt.Size() {
+		t.Errorf("Expected size %d, got %d", 1+TypeInt.Size(), in.Size())
+	}
+	if in.StackEffect() != -TypeInt.Size() {
+		t.Errorf("Expected stack effect %d, got %d", -TypeInt.Size(), in.StackEffect())
+	}
+
+	if LookupOpcode(0x01) != nil {
+		t.Errorf("Expected 0x01 to be unknown")
+	}
+}
+
+func TestInstructionSetReadme(t *testing.T) {
+	readme, err := ioutil.ReadFile("readme.md")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var table bytes.Buffer
+	err = WriteInstructionTable(&table)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !bytes.Contains(readme, table.Bytes()) {
+		t.Errorf("opcode table in readme.md is out of date, run go generate")
+	}
+}
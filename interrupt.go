@@ -0,0 +1,110 @@
+package virtualmachine
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// MaxInterrupts is the number of interrupts a virtual machine knows, numbered from 0
+const MaxInterrupts = 64
+
+// The interrupt vector table is an int per interrupt holding the address of its handler, 0 for none. An interrupt raised with
+// RaiseInterrupt is pending until Step takes it, between two instructions, with interrupts enabled. Taking it pushes the
+// program pointer like call does, disables interrupts and jumps to the handler; reti returns and enables them again. When
+// several are pending the lowest number goes first.
+
+// SetInterruptVectors places the vector table for count interrupts at address, count 0 removes it. Interrupts without a
+// table entry are thrown away when taken.
+func (vm *VirtualMachine) SetInterruptVectors(address int, count int) error {
+	if count < 0 || count > MaxInterrupts {
+		return fmt.Errorf("illegal number of interrupts %d", count)
+	}
+	if count > 0 && (address < 0 || address+count*IntSize > vm.memory.Size()) {
+		return newVMError(ErrIllegalAddress, address)
+	}
+
+	vm.interruptVectors = address
+	vm.interruptCount = count
+	return nil
+}
+
+// InterruptVectors returns the address of the vector table and the number of interrupts in it
+func (vm *VirtualMachine) InterruptVectors() (address int, count int) {
+	return vm.interruptVectors, vm.interruptCount
+}
+
+// RaiseInterrupt makes interrupt n pending, it is safe to call from any goroutine. Raising it again before it is taken has
+// no effect.
+func (vm *VirtualMachine) RaiseInterrupt(n int) error {
+	if n < 0 || n >= MaxInterrupts {
+		return fmt.Errorf("illegal interrupt %d", n)
+	}
+
+	for {
+		pending := atomic.LoadUint64(&vm.pendingInterrupts)
+		if atomic.CompareAndSwapUint64(&vm.pendingInterrupts, pending, pending|1<<uint(n)) {
+			return nil
+		}
+	}
+}
+
+// PendingInterrupts returns the interrupts that are raised but not taken yet, a bit per interrupt
+func (vm *VirtualMachine) PendingInterrupts() uint64 {
+	return atomic.LoadUint64(&vm.pendingInterrupts)
+}
+
+// InterruptsEnabled tells if pending interrupts are taken
+func (vm *VirtualMachine) InterruptsEnabled() bool {
+	return vm.interruptsEnabled
+}
+
+// interrupt takes the lowest pending interrupt, if any. It stays pending until the return address is pushed, so an
+// interrupt that faults is taken again once the fault is dealt with.
+func (vm *VirtualMachine) interrupt() error {
+	pending := atomic.LoadUint64(&vm.pendingInterrupts)
+	if pending == 0 {
+		return nil
+	}
+
+	n := 0
+	for pending&(1<<uint(n)) == 0 {
+		n++
+	}
+
+	if n >= vm.interruptCount {
+		vm.clearInterrupt(n)
+		return nil
+	}
+
+	address, err := vm.memory.GetInt(vm.interruptVectors + n*IntSize)
+	if err != nil {
+		return err
+	}
+	if address == 0 {
+		vm.clearInterrupt(n)
+		return nil
+	}
+	if address < 0 || address >= vm.memory.Size() {
+		return newVMError(ErrIllegalAddress, address)
+	}
+
+	err = vm.stack.PushInt(vm.programPointer)
+	if err != nil {
+		return err
+	}
+
+	vm.clearInterrupt(n)
+	vm.interruptsEnabled = false
+	vm.programPointer = address
+	return nil
+}
+
+// clearInterrupt makes interrupt n no longer pending, leaving the others as they are
+func (vm *VirtualMachine) clearInterrupt(n int) {
+	for {
+		pending := atomic.LoadUint64(&vm.pendingInterrupts)
+		if atomic.CompareAndSwapUint64(&vm.pendingInterrupts, pending, pending&^(1<<uint(n))) {
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package virtualmachine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testVectors = 96
+const testFlag = 120
+
+// interruptProgram enables interrupts and adds 1 and 2, the handler of interrupt 0 puts 7 in the flag
+func interruptProgram() *Program {
+	p := NewProgram()
+	p.WriteByte(0xFC)    // Opcode: ei
+	p.WriteByte(0x09)    // Opcode: push-int
+	p.WriteInt(1)        // Operant: 1
+	p.WriteByte(0x09)    // Opcode: push-int
+	p.WriteInt(2)        // Operant: 2
+	p.WriteByte(0x41)    // Opcode: add-int
+	p.WriteByte(0x00)    // Opcode: end
+	p.WriteByte(0x08)    // Opcode: push-byte (handler at 21)
+	p.WriteByte(0x07)    // Operant: 7
+	p.WriteByte(0x28)    // Opcode: put-byte()
+	p.WriteInt(testFlag) // Operant: flag
+	p.WriteByte(0xE2)    // Opcode: reti
+	for p.Size() < testVectors {
+		p.WriteByte(0x00)
+	}
+	p.WriteInt(21) // Data: vector of interrupt 0
+	p.WriteInt(0)  // Data: no vector for interrupt 1
+
+	return p
+}
+
+func interruptMachine(t *testing.T, p *Program) *VirtualMachine {
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.SetInterruptVectors(testVectors, 2)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return vm
+}
+
+func TestInterrupt(t *testing.T) {
+	vm := interruptMachine(t, interruptProgram())
+
+	// Pending until interrupts are enabled, after ei the handler runs before push-int
+	vm.RaiseInterrupt(0)
+	vm.RaiseInterrupt(1)
+
+	_, err := vm.Step()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.PendingInterrupts() != 3 || !vm.InterruptsEnabled() {
+		t.Errorf("Expected interrupts 0 and 1 pending and enabled, got %b", vm.PendingInterrupts())
+	}
+
+	_, err = vm.Step()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.ProgramPointer() != 23 || vm.InterruptsEnabled() {
+		t.Errorf("Expected to be in the handler with interrupts disabled, got %04X", vm.ProgramPointer())
+	}
+
+	// Interrupt 1 has no handler and disappears once reti enables interrupts again
+	err = vm.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.PendingInterrupts() != 0 {
+		t.Errorf("Expected no pending interrupts, got %b", vm.PendingInterrupts())
+	}
+
+	s := NewBuffer()
+	s.WriteInt(3)
+	err = vm.stack.Check(s.Value())
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	flag, err := vm.Memory().GetByte(testFlag)
+	if err != nil || flag != 7 {
+		t.Errorf("Expected the flag to be 7, got %d (%v)", flag, err)
+	}
+}
+
+func TestInterruptDisabled(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xFC) // Opcode: ei
+	p.WriteByte(0xFD) // Opcode: di
+	p.WriteByte(0x00) // Opcode: end
+
+	vm := interruptMachine(t, p)
+	err := vm.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vm.RaiseInterrupt(5)
+	if vm.PendingInterrupts() != 1<<5 || vm.InterruptsEnabled() {
+		t.Errorf("Expected interrupt 5 pending and disabled, got %b", vm.PendingInterrupts())
+	}
+}
+
+func TestInterruptFromGoroutine(t *testing.T) {
+	// Waits for the handler to set the flag
+	p := interruptProgram()
+	p.bytes[1] = 0x20                // Opcode: get-byte()
+	encodeInt(p.bytes[2:], testFlag) // Operant: flag
+	p.bytes[10] = 0xE8               // Opcode: jmpz-byte()
+	encodeInt(p.bytes[11:], 1)       // Operant: 1
+	p.bytes[19] = 0x00               // Opcode: end
+
+	vm := interruptMachine(t, p)
+
+	go func() {
+		time.Sleep(time.Millisecond)
+		vm.RaiseInterrupt(0)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := vm.RunContext(ctx)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+}
+
+func TestInterruptFaults(t *testing.T) {
+	vm := interruptMachine(t, interruptProgram())
+	err := vm.Memory().PutInt(testVectors, MEMORY_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vm.RaiseInterrupt(0)
+	err = vm.Run()
+
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || vmErr.Kind != ErrIllegalAddress || vmErr.Address != MEMORY_SIZE || vmErr.ProgramPointer != 1 {
+		t.Errorf("Expected: illegal address of the vector at 0001, got %v", err)
+	}
+
+	// An interrupt that can't push its return address stays pending
+	vm = interruptMachine(t, interruptProgram())
+	for vm.Stack().Pointer() < STACK_SIZE {
+		err = vm.Stack().PushInt(0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	vm.RaiseInterrupt(0)
+	err = vm.Run()
+	if !errors.Is(err, ErrStackOverflow) {
+		t.Errorf("Expected: stack overflow, got %v", err)
+	}
+	if vm.PendingInterrupts() != 1 {
+		t.Errorf("Expected interrupt 0 to be pending, got %b", vm.PendingInterrupts())
+	}
+
+	if vm.RaiseInterrupt(MaxInterrupts) == nil {
+		t.Errorf("Expected an illegal interrupt")
+	}
+	if vm.SetInterruptVectors(MEMORY_SIZE-IntSize, 2) == nil {
+		t.Errorf("Expected a vector table outside of memory to be refused")
+	}
+}
@@ -1,22 +1,50 @@
 package virtualmachine
 
 import (
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"strings"
-	"unsafe"
 
 	"github.com/ttacon/chalk"
 )
 
 type Memory struct {
-	memory []byte
+	memory  []byte
+	watch   MemoryWatch
+	devices []mapping // sorted on start, reads and writes in their range go to the device
+	regions []region  // sorted on start, protected memory
 }
 
+// MemoryWatch is told about every successful read or write through the Get... and Put... functions
+type MemoryWatch func(address int, size int, write bool)
+
 // -- Basic memory functions on bytes -------------------------------------------------------------------------------------------
 
 func (mem *Memory) GetByte(address int) (byte, error) {
 	if address < 0 || address >= len(mem.memory) {
-		return 0, fmt.Errorf("Memory Error")
+		return 0, newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, ByteSize, PermRead)
+	if err != nil {
+		return 0, err
+	}
+
+	device, offset, err := mem.device(address, ByteSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, 1, false)
+	}
+
+	if device != nil {
+		value, err := device.GetByte(offset)
+		return value, mem.deviceError(err, address)
 	}
 
 	return mem.memory[address], nil
@@ -24,7 +52,25 @@ func (mem *Memory) GetByte(address int) (byte, error) {
 
 func (mem *Memory) PutByte(address int, value byte) error {
 	if address < 0 || address >= len(mem.memory) {
-		return fmt.Errorf("Memory Error")
+		return newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, ByteSize, PermWrite)
+	if err != nil {
+		return err
+	}
+
+	device, offset, err := mem.device(address, ByteSize)
+	if err != nil {
+		return err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, 1, true)
+	}
+
+	if device != nil {
+		return mem.deviceError(device.PutByte(offset, value), address)
 	}
 
 	mem.memory[address] = value
@@ -35,52 +81,326 @@ func (mem *Memory) PutByte(address int, value byte) error {
 
 // GetInt fetches an Int
 func (mem *Memory) GetInt(address int) (int, error) {
-	var result int
+	if address < 0 || address+IntSize > len(mem.memory) {
+		return 0, newVMError(ErrMemory, address)
+	}
 
-	if address < 0 || address+(int)(unsafe.Sizeof(result)) > len(mem.memory) {
-		return 0, fmt.Errorf("Memory error")
+	err := mem.permit(address, IntSize, PermRead)
+	if err != nil {
+		return 0, err
 	}
 
-	result = *(*int)(unsafe.Pointer(&mem.memory[address]))
-	return result, nil
+	device, offset, err := mem.device(address, IntSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, IntSize, false)
+	}
+
+	if device != nil {
+		value, err := device.GetInt(offset)
+		return value, mem.deviceError(err, address)
+	}
+
+	value, ok := decodeInt(mem.memory[address:])
+	if !ok {
+		return 0, newVMError(ErrIntegerOverflow, address)
+	}
+
+	return value, nil
 }
 
 // PutInt stores an Int
 func (mem *Memory) PutInt(address int, value int) error {
-	if address < 0 || address+(int)(unsafe.Sizeof(value)) > len(mem.memory) {
-		return fmt.Errorf("Memory error")
+	if address < 0 || address+IntSize > len(mem.memory) {
+		return newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, IntSize, PermWrite)
+	if err != nil {
+		return err
+	}
+
+	device, offset, err := mem.device(address, IntSize)
+	if err != nil {
+		return err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, IntSize, true)
+	}
+
+	if device != nil {
+		return mem.deviceError(device.PutInt(offset, value), address)
+	}
+
+	encodeInt(mem.memory[address:], value)
+	return nil
+}
+
+// -- Basic memory functions on int16s ------------------------------------------------------------------------------------------
+
+// GetInt16 fetches an Int16
+func (mem *Memory) GetInt16(address int) (int16, error) {
+	if address < 0 || address+Int16Size > len(mem.memory) {
+		return 0, newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, Int16Size, PermRead)
+	if err != nil {
+		return 0, err
+	}
+	if mem.mapped(address, Int16Size) {
+		return 0, newDeviceError(address, errDeviceType)
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, Int16Size, false)
+	}
+
+	return decodeInt16(mem.memory[address:]), nil
+}
+
+// PutInt16 stores an Int16
+func (mem *Memory) PutInt16(address int, value int16) error {
+	if address < 0 || address+Int16Size > len(mem.memory) {
+		return newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, Int16Size, PermWrite)
+	if err != nil {
+		return err
+	}
+	if mem.mapped(address, Int16Size) {
+		return newDeviceError(address, errDeviceType)
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, Int16Size, true)
+	}
+
+	encodeInt16(mem.memory[address:], value)
+	return nil
+}
+
+// -- Basic memory functions on int32s ------------------------------------------------------------------------------------------
+
+// GetInt32 fetches an Int32
+func (mem *Memory) GetInt32(address int) (int32, error) {
+	if address < 0 || address+Int32Size > len(mem.memory) {
+		return 0, newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, Int32Size, PermRead)
+	if err != nil {
+		return 0, err
+	}
+	if mem.mapped(address, Int32Size) {
+		return 0, newDeviceError(address, errDeviceType)
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, Int32Size, false)
+	}
+
+	return decodeInt32(mem.memory[address:]), nil
+}
+
+// PutInt32 stores an Int32
+func (mem *Memory) PutInt32(address int, value int32) error {
+	if address < 0 || address+Int32Size > len(mem.memory) {
+		return newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, Int32Size, PermWrite)
+	if err != nil {
+		return err
+	}
+	if mem.mapped(address, Int32Size) {
+		return newDeviceError(address, errDeviceType)
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, Int32Size, true)
 	}
 
-	*(*int)(unsafe.Pointer(&mem.memory[address])) = value
+	encodeInt32(mem.memory[address:], value)
 	return nil
 }
 
 // -- Basic memory functions on Float64 -----------------------------------------------------------------------------------------
 
 func (mem *Memory) GetFloat(address int) (float64, error) {
-	var result float64
+	if address < 0 || address+FloatSize > len(mem.memory) {
+		return 0, newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, FloatSize, PermRead)
+	if err != nil {
+		return 0, err
+	}
+
+	device, offset, err := mem.device(address, FloatSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, FloatSize, false)
+	}
 
-	if address < 0 || address+(int)(unsafe.Sizeof(result)) > len(mem.memory) {
-		return 0, fmt.Errorf("Memory error")
+	if device != nil {
+		value, err := device.GetFloat(offset)
+		return value, mem.deviceError(err, address)
 	}
 
-	result = *(*float64)(unsafe.Pointer(&mem.memory[address]))
-	return result, nil
+	return decodeFloat(mem.memory[address:]), nil
 }
 
 func (mem *Memory) PutFloat(address int, value float64) error {
-	if address < 0 || address+(int)(unsafe.Sizeof(value)) > len(mem.memory) {
-		return fmt.Errorf("Memory error")
+	if address < 0 || address+FloatSize > len(mem.memory) {
+		return newVMError(ErrMemory, address)
+	}
+
+	err := mem.permit(address, FloatSize, PermWrite)
+	if err != nil {
+		return err
+	}
+
+	device, offset, err := mem.device(address, FloatSize)
+	if err != nil {
+		return err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, FloatSize, true)
+	}
+
+	if device != nil {
+		return mem.deviceError(device.PutFloat(offset, value), address)
 	}
 
-	*(*float64)(unsafe.Pointer(&mem.memory[address])) = value
+	encodeFloat(mem.memory[address:], value)
 	return nil
 }
 
+// -- Basic memory functions on strings -----------------------------------------------------------------------------------------
+
+// GetString fetches the string at address: an int holding the length in bytes followed by the UTF-8 bytes
+func (mem *Memory) GetString(address int) (string, error) {
+	if address < 0 || address+IntSize > len(mem.memory) {
+		return "", newVMError(ErrMemory, address)
+	}
+
+	length, ok := decodeInt(mem.memory[address:])
+	if !ok || length < 0 || length > len(mem.memory)-address-IntSize {
+		return "", newVMError(ErrMemory, address)
+	}
+	if mem.mapped(address, IntSize+length) {
+		return "", newDeviceError(address, errDeviceType)
+	}
+
+	err := mem.permit(address, IntSize+length, PermRead)
+	if err != nil {
+		return "", err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, IntSize+length, false)
+	}
+
+	return string(mem.memory[address+IntSize : address+IntSize+length]), nil
+}
+
+// PutString stores a string as its length followed by its bytes
+func (mem *Memory) PutString(address int, value string) error {
+	if address < 0 || len(value) > len(mem.memory)-address-IntSize {
+		return newVMError(ErrMemory, address)
+	}
+	if mem.mapped(address, IntSize+len(value)) {
+		return newDeviceError(address, errDeviceType)
+	}
+
+	err := mem.permit(address, IntSize+len(value), PermWrite)
+	if err != nil {
+		return err
+	}
+
+	if mem.watch != nil {
+		mem.watch(address, IntSize+len(value), true)
+	}
+
+	encodeInt(mem.memory[address:], len(value))
+	copy(mem.memory[address+IntSize:], value)
+	return nil
+}
+
+// -- Encoding ------------------------------------------------------------------------------------------------------------------
+// Values have the same layout in memory, on the stack, in bytecode and in snapshots, whatever the host: an int is a 64-bit
+// two's complement, int16 and int32 are 16 and 32-bit two's complements and a float is an IEEE 754 binary64, all little endian.
+
+const (
+	ByteSize  = 1
+	Int16Size = 2
+	Int32Size = 4
+	IntSize   = 8
+	FloatSize = 8
+)
+
+// decodeInt reads an int, ok is false when it doesn't fit in the int of the host, as happens on 32-bit hosts
+func decodeInt(b []byte) (value int, ok bool) {
+	wide := decodeInt64(b)
+	return int(wide), fitsInt(wide)
+}
+
+// fitsInt tells if value, a 64-bit int of the virtual machine, fits the int of the host
+func fitsInt(value int64) bool {
+	return int64(int(value)) == value
+}
+
+func decodeInt64(b []byte) int64 {
+	return int64(binary.LittleEndian.Uint64(b))
+}
+
+func encodeInt(b []byte, value int) {
+	binary.LittleEndian.PutUint64(b, uint64(int64(value)))
+}
+
+func decodeInt16(b []byte) int16 {
+	return int16(binary.LittleEndian.Uint16(b))
+}
+
+func encodeInt16(b []byte, value int16) {
+	binary.LittleEndian.PutUint16(b, uint16(value))
+}
+
+func decodeInt32(b []byte) int32 {
+	return int32(binary.LittleEndian.Uint32(b))
+}
+
+func encodeInt32(b []byte, value int32) {
+	binary.LittleEndian.PutUint32(b, uint32(value))
+}
+
+func decodeFloat(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func encodeFloat(b []byte, value float64) {
+	binary.LittleEndian.PutUint64(b, math.Float64bits(value))
+}
+
 // -- Support functions ---------------------------------------------------------------------------------------------------------
 
-// Show displays the content of the memory in hex
+// Show displays the content of the memory in hex on standard output
 func (mem *Memory) Show(programPointer int) {
+	mem.ShowTo(os.Stdout, programPointer)
+}
+
+// ShowTo displays the content of the memory in hex on w, underlining the byte at programPointer
+func (mem *Memory) ShowTo(w io.Writer, programPointer int) {
 	// chalk styles
 	headerStyle := chalk.White.NewStyle().WithBackground(chalk.Green).WithTextStyle(chalk.Bold)
 	defaultStyle := chalk.White.NewStyle().WithBackground(chalk.Green)
@@ -92,24 +412,24 @@ func (mem *Memory) Show(programPointer int) {
 	headerText := "Memory"
 	lineSpaces := lineLength - len(headerText)
 	headerText = strings.Repeat(" ", lineSpaces/2) + headerText + strings.Repeat(" ", lineSpaces-lineSpaces/2)
-	fmt.Println(headerStyle.Style(headerText))
+	fmt.Fprintln(w, headerStyle.Style(headerText))
 
 	// Memory contents
 	for i, v := range mem.memory {
 		value := fmt.Sprintf("%02X", v)
 		if i == programPointer {
-			fmt.Print(pointerStyle.Style(value))
+			fmt.Fprint(w, pointerStyle.Style(value))
 		} else {
-			fmt.Print(defaultStyle.Style(value))
+			fmt.Fprint(w, defaultStyle.Style(value))
 		}
-		fmt.Print(defaultStyle.Style(" "))
+		fmt.Fprint(w, defaultStyle.Style(" "))
 		if (i+1)%lineItems == 0 {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 
 	// Empty line at the end
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // Check compares (a part of) memory with some expected value, used for testing
@@ -123,6 +443,23 @@ func (mem *Memory) Check(expectedValue []byte) error {
 	return nil
 }
 
+// Peek copies size bytes from address as they are stored, for tools like a debugger or disassembler: the watch and the
+// devices don't see it and protection doesn't apply
+func (mem *Memory) Peek(address int, size int) ([]byte, error) {
+	if address < 0 || size < 0 || address+size > len(mem.memory) {
+		return nil, newVMError(ErrMemory, address)
+	}
+
+	value := make([]byte, size)
+	copy(value, mem.memory[address:address+size])
+	return value, nil
+}
+
+// SetWatch installs a function that is told about every read and write, nil removes it
+func (mem *Memory) SetWatch(watch MemoryWatch) {
+	mem.watch = watch
+}
+
 // Size returns the size of the memory
 func (mem *Memory) Size() (size int) {
 	return len(mem.memory)
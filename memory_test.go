@@ -1,8 +1,9 @@
 package virtualmachine
 
 import (
+	"errors"
+	"strconv"
 	"testing"
-	"unsafe"
 )
 
 // -- Tests ---------------------------------------------------------------------------------------------------------------------
@@ -24,7 +25,7 @@ func TestMemoryPutByte(t *testing.T) {
 	}
 
 	// Test boundary
-	err = mem.PutByte(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue)), testValue)
+	err = mem.PutByte(MEMORY_SIZE-ByteSize, testValue)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -61,11 +62,11 @@ func TestMemoryGetByte(t *testing.T) {
 	}
 
 	// Last possible byte
-	err = mem.PutByte(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue)), testValue)
+	err = mem.PutByte(MEMORY_SIZE-ByteSize, testValue)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
-	value, err = mem.GetByte(MEMORY_SIZE - (int)(unsafe.Sizeof(testValue)))
+	value, err = mem.GetByte(MEMORY_SIZE - ByteSize)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -102,7 +103,7 @@ func TestMemoryPutInt(t *testing.T) {
 	}
 
 	// Last possible location
-	err = mem.PutInt(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue)), testValue)
+	err = mem.PutInt(MEMORY_SIZE-IntSize, testValue)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -112,7 +113,7 @@ func TestMemoryPutInt(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected memory error")
 	}
-	err = mem.PutInt(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue))+1, testValue)
+	err = mem.PutInt(MEMORY_SIZE-IntSize+1, testValue)
 	if err == nil {
 		t.Errorf("Expected memory error")
 	}
@@ -137,11 +138,11 @@ func TestMemoryGetInt(t *testing.T) {
 	}
 
 	// Last possible byte
-	err = mem.PutInt(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue)), testValue)
+	err = mem.PutInt(MEMORY_SIZE-IntSize, testValue)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
-	value, err = mem.GetInt(MEMORY_SIZE - (int)(unsafe.Sizeof(testValue)))
+	value, err = mem.GetInt(MEMORY_SIZE - IntSize)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -154,7 +155,7 @@ func TestMemoryGetInt(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected a memory error")
 	}
-	_, err = mem.GetInt(MEMORY_SIZE - (int)(unsafe.Sizeof(testValue)) + 1)
+	_, err = mem.GetInt(MEMORY_SIZE - IntSize + 1)
 	if err == nil {
 		t.Errorf("Expected a memory error")
 	}
@@ -174,11 +175,11 @@ func TestMemoryPutFloat(t *testing.T) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x29, 0x40}
 	err = mem.Check(expectMem[:])
 	if err != nil {
-		t.Errorf("Expected %v, got %v", expectMem, mem.memory[:(int)(unsafe.Sizeof(testValue))])
+		t.Errorf("Expected %v, got %v", expectMem, mem.memory[:FloatSize])
 	}
 
 	// Last possible location
-	err = mem.PutFloat(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue)), testValue)
+	err = mem.PutFloat(MEMORY_SIZE-FloatSize, testValue)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -188,7 +189,7 @@ func TestMemoryPutFloat(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected memory error")
 	}
-	err = mem.PutFloat(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue))+1, testValue)
+	err = mem.PutFloat(MEMORY_SIZE-FloatSize+1, testValue)
 	if err == nil {
 		t.Errorf("Expected memory error")
 	}
@@ -213,11 +214,11 @@ func TestMemoryGetFloat(t *testing.T) {
 	}
 
 	// Last possible byte
-	err = mem.PutFloat(MEMORY_SIZE-(int)(unsafe.Sizeof(testValue)), testValue)
+	err = mem.PutFloat(MEMORY_SIZE-FloatSize, testValue)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
-	value, err = mem.GetFloat(MEMORY_SIZE - (int)(unsafe.Sizeof(testValue)))
+	value, err = mem.GetFloat(MEMORY_SIZE - FloatSize)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -230,8 +231,156 @@ func TestMemoryGetFloat(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected a memory error")
 	}
-	_, err = mem.GetFloat(MEMORY_SIZE - (int)(unsafe.Sizeof(testValue)) + 1)
+	_, err = mem.GetFloat(MEMORY_SIZE - FloatSize + 1)
 	if err == nil {
 		t.Errorf("Expected a memory error")
 	}
 }
+
+func TestMemoryWatch(t *testing.T) {
+	type access struct {
+		address int
+		size    int
+		write   bool
+	}
+	var accesses []access
+
+	mem := NewMemory(MEMORY_SIZE)
+	mem.SetWatch(func(address int, size int, write bool) {
+		accesses = append(accesses, access{address, size, write})
+	})
+
+	mem.PutInt(8, 1)
+	mem.GetByte(9)
+	mem.GetFloat(MEMORY_SIZE) // fails, so not reported
+
+	expected := []access{{8, IntSize, true}, {9, 1, false}}
+	if len(accesses) != len(expected) || accesses[0] != expected[0] || accesses[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, accesses)
+	}
+
+	mem.SetWatch(nil)
+	mem.GetByte(9)
+	if len(accesses) != len(expected) {
+		t.Errorf("Expected watch to be removed")
+	}
+}
+
+func TestMemoryLayout(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+
+	// Ints are 64-bit little endian, whatever the host
+	err := mem.PutInt(0, 0x0102030405060708)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = mem.PutInt(IntSize, -2)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Floats are IEEE 754 binary64, little endian
+	err = mem.PutFloat(2*IntSize, -1.0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectMem := [...]byte{
+		0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01,
+		0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0xBF}
+	err = mem.Check(expectMem[:])
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	value, err := mem.GetInt(IntSize)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if value != -2 {
+		t.Errorf("Expected -2, got %d", value)
+	}
+}
+
+func TestMemoryString(t *testing.T) {
+	testValue := "héllo, world"
+
+	mem := NewMemory(MEMORY_SIZE)
+
+	// Last possible byte
+	address := MEMORY_SIZE - IntSize - len(testValue)
+	err := mem.PutString(address, testValue)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	value, err := mem.GetString(address)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if value != testValue {
+		t.Errorf("Expected %q, got %q", testValue, value)
+	}
+
+	// Out of bounds checks
+	err = mem.PutString(address+1, testValue)
+	if err == nil {
+		t.Errorf("Expected a memory error")
+	}
+	err = mem.PutInt(0, MEMORY_SIZE)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	_, err = mem.GetString(0)
+	if err == nil {
+		t.Errorf("Expected a memory error")
+	}
+	err = mem.PutInt(0, -1)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	_, err = mem.GetString(0)
+	if err == nil {
+		t.Errorf("Expected a memory error")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	mem := NewMemory(16)
+	err := mem.PutInt(8, 42)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	watched := false
+	mem.SetWatch(func(address int, size int, write bool) { watched = true })
+	err = mem.Protect(0, 16, PermWrite)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	value, err := mem.Peek(8, IntSize)
+	if err != nil || decodeInt64(value) != 42 || watched {
+		t.Errorf("Expected 42 without a watch, got % X (%v), watched %v", value, err, watched)
+	}
+
+	_, err = mem.Peek(12, IntSize)
+	if !errors.Is(err, ErrMemory) {
+		t.Errorf("Expected: memory error, got %v", err)
+	}
+}
+
+func TestMemoryGetIntWide(t *testing.T) {
+	// An int that needs more than 32 bits, a 32-bit host can't hold it
+	mem := NewMemory(16)
+	copy(mem.memory, []byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00})
+
+	value, err := mem.GetInt(0)
+	if strconv.IntSize == 32 {
+		if !errors.Is(err, ErrIntegerOverflow) {
+			t.Errorf("Expected: integer overflow, got %d (%v)", value, err)
+		}
+	} else if err != nil || int64(value) != 1<<32 {
+		t.Errorf("Expected %d, got %d (%v)", int64(1)<<32, value, err)
+	}
+}
@@ -0,0 +1,146 @@
+package virtualmachine
+
+import (
+	"errors"
+	"sort"
+)
+
+// Permission is a bit set of what may be done with a region of memory
+type Permission uint8
+
+const (
+	PermRead  Permission = 1 << iota // Get... functions
+	PermWrite                        // Put... functions
+	PermExec                         // running instructions
+
+	PermAll Permission = PermRead | PermWrite | PermExec
+)
+
+// region is a range of memory with its permissions, from start up to end
+type region struct {
+	start int
+	end   int
+	perm  Permission
+	guard error // raised on any access instead of ErrProtection, for freed heap blocks in debug mode
+}
+
+var errNotReadable = errors.New("not readable")
+var errNotWritable = errors.New("not writable")
+var errNotExecutable = errors.New("not executable")
+
+// -- Protecting memory ---------------------------------------------------------------------------------------------------------
+// Memory without regions can be used for anything, which costs nothing but a length check per access
+
+// Protect gives size bytes from start the permissions perm, replacing what they had before. Memory outside every protected
+// region keeps all permissions.
+func (mem *Memory) Protect(start int, size int, perm Permission) error {
+	if size <= 0 || start < 0 || start+size > len(mem.memory) {
+		return newVMError(ErrIllegalAddress, start)
+	}
+
+	mem.setRegion(region{start: start, end: start + size, perm: perm})
+	return nil
+}
+
+// guard makes every access to size bytes from start raise kind
+func (mem *Memory) guard(start int, size int, kind error) {
+	if size > 0 {
+		mem.setRegion(region{start: start, end: start + size, guard: kind})
+	}
+}
+
+// unguard removes the guards between start and end, leaving the protected regions
+func (mem *Memory) unguard(start int, end int) {
+	regions := mem.regions[:0]
+	for _, r := range mem.regions {
+		if r.guard == nil || r.start < start || r.end > end {
+			regions = append(regions, r)
+		}
+	}
+
+	if len(regions) == 0 {
+		regions = nil
+	}
+	mem.regions = regions
+}
+
+// setRegion puts r over what was there before, cutting the regions it overlaps
+func (mem *Memory) setRegion(r region) {
+	regions := []region{r}
+	for _, old := range mem.regions {
+		if old.start < r.start {
+			before := old
+			before.end = minInt(old.end, r.start)
+			regions = append(regions, before)
+		}
+		if old.end > r.end {
+			after := old
+			after.start = maxInt(old.start, r.end)
+			regions = append(regions, after)
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].start < regions[j].start })
+
+	mem.regions = regions
+}
+
+// ClearProtection gives all memory every permission again
+func (mem *Memory) ClearProtection() {
+	mem.regions = nil
+}
+
+// Permissions returns the permissions of the byte at address
+func (mem *Memory) Permissions(address int) Permission {
+	i := mem.region(address)
+	if i < len(mem.regions) && mem.regions[i].start <= address {
+		return mem.regions[i].perm
+	}
+
+	return PermAll
+}
+
+// region returns the index of the first region ending after address, there can be a lot of them with heap debug mode
+// guarding every freed block
+func (mem *Memory) region(address int) int {
+	return sort.Search(len(mem.regions), func(i int) bool { return mem.regions[i].end > address })
+}
+
+// permit checks that size bytes from address allow perm, raising ErrProtection at address when they don't
+func (mem *Memory) permit(address int, size int, perm Permission) error {
+	for _, r := range mem.regions[mem.region(address):] {
+		if r.start >= address+size {
+			break
+		}
+		if r.guard != nil {
+			return newVMError(r.guard, address)
+		}
+		if r.perm&perm != perm {
+			err := newVMError(ErrProtection, address)
+			switch perm {
+			case PermRead:
+				err.Cause = errNotReadable
+			case PermWrite:
+				err.Cause = errNotWritable
+			default:
+				err.Cause = errNotExecutable
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
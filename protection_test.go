@@ -0,0 +1,168 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+)
+
+func protectedMachine(t *testing.T) *VirtualMachine {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Protection = true
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return vm
+}
+
+func TestMemoryProtect(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+
+	err := mem.Protect(0, MEMORY_SIZE, PermRead|PermWrite)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = mem.Protect(16, 16, PermRead|PermExec)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = mem.Protect(24, 4, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tests := []struct {
+		address  int
+		expected Permission
+	}{
+		{0, PermRead | PermWrite},
+		{15, PermRead | PermWrite},
+		{16, PermRead | PermExec},
+		{23, PermRead | PermExec},
+		{24, 0},
+		{27, 0},
+		{28, PermRead | PermExec},
+		{32, PermRead | PermWrite},
+	}
+
+	for _, test := range tests {
+		if perm := mem.Permissions(test.address); perm != test.expected {
+			t.Errorf("%d: expected %03b, got %03b", test.address, test.expected, perm)
+		}
+	}
+
+	// Reads and writes touching a region that doesn't permit them fault at their address
+	err = mem.PutInt(10, 1)
+	if !errors.Is(err, ErrProtection) || !errors.Is(err, errNotWritable) {
+		t.Errorf("Expected: not writable, got %v", err)
+	}
+	_, err = mem.GetByte(26)
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || vmErr.Kind != ErrProtection || vmErr.Address != 26 || vmErr.Cause != errNotReadable {
+		t.Errorf("Expected: not readable at 26, got %v", err)
+	}
+	_, err = mem.GetInt(16)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	err = mem.PutString(32, "data")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Illegal ranges
+	if mem.Protect(MEMORY_SIZE-4, 8, PermAll) == nil || mem.Protect(0, 0, PermAll) == nil {
+		t.Errorf("Expected ranges outside of memory to be refused")
+	}
+
+	mem.ClearProtection()
+	err = mem.PutInt(16, 1)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestMemoryGuards(t *testing.T) {
+	mem := NewMemory(MEMORY_SIZE)
+
+	// Like the freed blocks in heap debug mode: many small guards with gaps between them
+	for start := 0; start < MEMORY_SIZE; start += 4 {
+		mem.guard(start+1, 2, ErrUseAfterFree)
+	}
+
+	for address := 0; address < MEMORY_SIZE; address++ {
+		_, err := mem.GetByte(address)
+		guarded := address%4 == 1 || address%4 == 2
+		if guarded != errors.Is(err, ErrUseAfterFree) {
+			t.Errorf("%d: expected guarded %v, got %v", address, guarded, err)
+		}
+	}
+	err := mem.PutInt16(4, 1)
+	if !errors.Is(err, ErrUseAfterFree) {
+		t.Errorf("Expected: use after free reaching into the next guard, got %v", err)
+	}
+}
+
+func TestProtectedProgram(t *testing.T) {
+	// Writing into the program faults
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(42)    // Operant: 42
+	p.WriteByte(0x29) // Opcode: put-int()
+	p.WriteInt(20)    // Operant: 20
+	p.WriteByte(0x00) // Opcode: end
+	p.WriteInt(0)     // Data: 20
+
+	vm := protectedMachine(t)
+	err := p.RunOn(vm, nil, nil)
+
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || vmErr.Kind != ErrProtection || vmErr.Address != 20 || vmErr.ProgramPointer != 9 {
+		t.Errorf("Expected: not writable at 20 by 0009, got %v", err)
+	}
+
+	// Writing just after it, into the heap, is fine
+	p = NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(42)    // Operant: 42
+	p.WriteByte(0x29) // Opcode: put-int()
+	p.WriteInt(19)    // Operant: 19
+	p.WriteByte(0x00) // Opcode: end
+
+	m := NewBuffer()
+	m.Copy(&p.Buffer)
+	m.WriteInt(42)
+
+	err = p.RunOn(vm, NewBuffer(), m)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestProtectedExecution(t *testing.T) {
+	tests := []struct {
+		name    string
+		address int
+	}{
+		{"heap", 20},
+		{"stack", MEMORY_SIZE - STACK_SIZE},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.address) // Operant: address
+		p.WriteByte(0xF8)        // Opcode: call
+		p.WriteByte(0x00)        // Opcode: end
+
+		err := p.RunOn(protectedMachine(t), nil, nil)
+
+		var vmErr *VMError
+		if !errors.As(err, &vmErr) || vmErr.Kind != ErrProtection || vmErr.Cause != errNotExecutable ||
+			vmErr.Address != test.address || vmErr.ProgramPointer != test.address {
+			t.Errorf("%s: expected not executable at %04X, got %v", test.name, test.address, err)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package virtualmachine
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A snapshot is a header, the state of the virtual machine and the stack, the protected regions of memory, the address ranges
+// of the devices, the global slots of the garbage collector, followed by the contents of memory. Numbers are little endian.
+// The tracer, the memory watch, the devices themselves, the console and the host functions belong to the world around the
+// virtual machine and are not part of it.
+
+// SnapshotVersion is the version of the snapshots Snapshot writes. The format only changes, and the version only goes up,
+// between releases; everything in this release writes and reads version 1.
+const SnapshotVersion = 1
+
+// ErrSnapshot is returned when restoring a snapshot that holds a state the virtual machine can't be in
+var ErrSnapshot = errors.New("corrupt snapshot")
+
+var snapshotMagic = [4]byte{'V', 'M', 'S', 'S'}
+
+type snapshotHeader struct {
+	Magic     [4]byte
+	Version   uint16
+	IntSize   uint8 // ints in memory are only readable by a virtual machine with the same size
+	FloatSize uint8
+}
+
+type snapshotState struct {
+	ProgramPointer  int64
+	LoadAddress     int64
+	Protection      uint8
+	MaxSteps        int64
+	Steps           int64
+	FaultHandler    int64
+	FloatDivision   uint8
+	IntegerOverflow uint8
+	Extensions      uint64
+
+	StackOffset  int64
+	StackSize    int64
+	StackPointer int64
+	StackFlags   uint8 // stackOverflowFlag and stackUnderflowFlag
+
+	HeapStart    int64
+	HeapEnd      int64
+	HeapTop      int64
+	HeapDebug    uint8
+	HeapAllocs   int64
+	HeapFrees    int64
+	HeapReallocs int64
+	HeapFailures int64
+	HeapInUse    int64
+	HeapPeak     int64
+
+	GCThreshold   int64
+	GCAllocated   int64
+	GCCollections int64
+	GCFreed       int64
+	GCFreedBytes  int64
+	GCLive        int64
+
+	InterruptVectors  int64
+	InterruptCount    int64
+	InterruptsEnabled uint8
+	PendingInterrupts uint64
+
+	Regions    int64 // number of protected regions following the state
+	Devices    int64 // number of device ranges following the regions
+	GCRoots    int64 // number of global slot addresses following the devices
+	MemorySize int64
+}
+
+type snapshotRegion struct {
+	Start int64
+	End   int64
+	Perm  uint8
+	Guard uint8 // 1 for a freed heap block
+}
+
+type snapshotDevice struct {
+	Start int64
+	Size  int64
+}
+
+const (
+	stackOverflowFlag  = 1 << 0
+	stackUnderflowFlag = 1 << 1
+)
+
+// Snapshot writes the complete state of the virtual machine to w
+func (vm *VirtualMachine) Snapshot(w io.Writer) error {
+	header := snapshotHeader{
+		Magic:     snapshotMagic,
+		Version:   SnapshotVersion,
+		IntSize:   uint8(TypeInt.Size()),
+		FloatSize: uint8(TypeFloat.Size()),
+	}
+
+	state := snapshotState{
+		ProgramPointer:  int64(vm.programPointer),
+		LoadAddress:     int64(vm.loadAddress),
+		MaxSteps:        int64(vm.maxSteps),
+		Steps:           int64(vm.steps),
+		FaultHandler:    int64(vm.faultHandler),
+		FloatDivision:   uint8(vm.floatDivision),
+		IntegerOverflow: uint8(vm.integerOverflow),
+		Extensions:      uint64(vm.extensions),
+		StackOffset:     int64(vm.stack.offset),
+		StackSize:       int64(vm.stack.size),
+		StackPointer:    int64(vm.stack.pointer),
+		HeapStart:       int64(vm.heap.start),
+		HeapEnd:         int64(vm.heap.end),
+		HeapTop:         int64(vm.heap.top),
+		HeapAllocs:      int64(vm.heap.allocs),
+		HeapFrees:       int64(vm.heap.frees),
+		HeapReallocs:    int64(vm.heap.reallocs),
+		HeapFailures:    int64(vm.heap.failures),
+		HeapInUse:       int64(vm.heap.inUse),
+		HeapPeak:        int64(vm.heap.peak),
+
+		GCThreshold:   int64(vm.heap.gc.threshold),
+		GCAllocated:   int64(vm.heap.gc.allocated),
+		GCCollections: int64(vm.heap.gc.stats.Collections),
+		GCFreed:       int64(vm.heap.gc.stats.Freed),
+		GCFreedBytes:  int64(vm.heap.gc.stats.FreedBytes),
+		GCLive:        int64(vm.heap.gc.stats.Live),
+
+		InterruptVectors:  int64(vm.interruptVectors),
+		InterruptCount:    int64(vm.interruptCount),
+		PendingInterrupts: vm.PendingInterrupts(),
+
+		Regions:    int64(len(vm.memory.regions)),
+		Devices:    int64(len(vm.memory.devices)),
+		GCRoots:    int64(len(vm.heap.gc.roots)),
+		MemorySize: int64(len(vm.memory.memory)),
+	}
+	if vm.stack.overflow {
+		state.StackFlags |= stackOverflowFlag
+	}
+	if vm.stack.underflow {
+		state.StackFlags |= stackUnderflowFlag
+	}
+	if vm.interruptsEnabled {
+		state.InterruptsEnabled = 1
+	}
+	if vm.protection {
+		state.Protection = 1
+	}
+	if vm.heap.debug {
+		state.HeapDebug = 1
+	}
+
+	regions := make([]snapshotRegion, len(vm.memory.regions))
+	for i, r := range vm.memory.regions {
+		regions[i] = snapshotRegion{Start: int64(r.start), End: int64(r.end), Perm: uint8(r.perm)}
+		if r.guard != nil {
+			regions[i].Guard = 1
+		}
+	}
+
+	devices := make([]snapshotDevice, len(vm.memory.devices))
+	for i, m := range vm.memory.devices {
+		devices[i] = snapshotDevice{Start: int64(m.start), Size: int64(m.size)}
+	}
+
+	roots := make([]int64, len(vm.heap.gc.roots))
+	for i, root := range vm.heap.gc.roots {
+		roots[i] = int64(root)
+	}
+
+	err := binary.Write(w, binary.LittleEndian, &header)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.LittleEndian, &state)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.LittleEndian, regions)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.LittleEndian, devices)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.LittleEndian, roots)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(vm.memory.memory)
+	return err
+}
+
+// RestoreVirtualMachine reads a snapshot written by Snapshot, the virtual machine continues exactly where the snapshot was
+// taken. Set a tracer, memory watch, input, output or host functions again when needed. Devices have to be attached again
+// with AttachDevice over the same ranges, until then the instructions using them fault with ErrDevice.
+func RestoreVirtualMachine(r io.Reader) (vm *VirtualMachine, err error) {
+	var header snapshotHeader
+	err = binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot header: %w", err)
+	}
+	if header.Magic != snapshotMagic {
+		return nil, fmt.Errorf("not a snapshot")
+	}
+	if header.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", header.Version)
+	}
+	if int(header.IntSize) != TypeInt.Size() || int(header.FloatSize) != TypeFloat.Size() {
+		return nil, fmt.Errorf("snapshot has %d byte ints and %d byte floats", header.IntSize, header.FloatSize)
+	}
+
+	var state snapshotState
+	err = binary.Read(r, binary.LittleEndian, &state)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot state: %w", err)
+	}
+	if state.MemorySize < 0 || state.MaxSteps < 0 || state.Steps < 0 || state.Regions < 0 || state.Devices < 0 ||
+		state.GCRoots < 0 || state.GCThreshold < 0 {
+		return nil, ErrSnapshot
+	}
+
+	// Regions are sorted and don't overlap, read what is there rather than trusting the number with a huge allocation
+	var regions []region
+	for i := int64(0); i < state.Regions; i++ {
+		var sr snapshotRegion
+		err = binary.Read(r, binary.LittleEndian, &sr)
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot regions: %w", err)
+		}
+		if sr.Start < 0 || sr.Start >= sr.End || sr.End > state.MemorySize ||
+			(len(regions) > 0 && sr.Start < int64(regions[len(regions)-1].end)) {
+			return nil, fmt.Errorf("%w: illegal protected region", ErrSnapshot)
+		}
+		regions = append(regions, region{start: int(sr.Start), end: int(sr.End), perm: Permission(sr.Perm)})
+		if sr.Guard != 0 {
+			regions[len(regions)-1].guard = ErrUseAfterFree
+		}
+	}
+
+	var devices []mapping
+	for i := int64(0); i < state.Devices; i++ {
+		var sd snapshotDevice
+		err = binary.Read(r, binary.LittleEndian, &sd)
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot devices: %w", err)
+		}
+		if sd.Start < 0 || sd.Size <= 0 || sd.Start+sd.Size > state.MemorySize ||
+			(len(devices) > 0 && sd.Start < int64(devices[len(devices)-1].start+devices[len(devices)-1].size)) {
+			return nil, fmt.Errorf("%w: illegal device", ErrSnapshot)
+		}
+		devices = append(devices, mapping{start: int(sd.Start), size: int(sd.Size)})
+	}
+
+	var roots []int
+	for i := int64(0); i < state.GCRoots; i++ {
+		var root int64
+		err = binary.Read(r, binary.LittleEndian, &root)
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot garbage collector roots: %w", err)
+		}
+		if root < 0 || root+int64(IntSize) > state.MemorySize {
+			return nil, fmt.Errorf("%w: illegal garbage collector root", ErrSnapshot)
+		}
+		roots = append(roots, int(root))
+	}
+
+	// Read what is there rather than trusting the size with a huge allocation
+	contents, err := io.ReadAll(io.LimitReader(r, state.MemorySize))
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot memory: %w", err)
+	}
+	if int64(len(contents)) != state.MemorySize {
+		return nil, fmt.Errorf("reading snapshot memory: %w", io.ErrUnexpectedEOF)
+	}
+	memory := &Memory{memory: contents, devices: devices, regions: regions}
+
+	stack, err := newStackAt(memory, int(state.StackOffset), int(state.StackSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshot, err)
+	}
+	if state.StackPointer < 0 || state.StackPointer > state.StackSize {
+		return nil, fmt.Errorf("%w: illegal stack pointer", ErrSnapshot)
+	}
+	stack.pointer = int(state.StackPointer)
+	stack.overflow = state.StackFlags&stackOverflowFlag != 0
+	stack.underflow = state.StackFlags&stackUnderflowFlag != 0
+
+	if state.HeapStart < 0 || state.HeapTop < state.HeapStart || state.HeapEnd < state.HeapTop ||
+		state.HeapEnd > state.MemorySize {
+		return nil, fmt.Errorf("%w: illegal heap", ErrSnapshot)
+	}
+	heap := newHeap(memory, stack, int(state.HeapStart), int(state.HeapEnd))
+	heap.top = int(state.HeapTop)
+	heap.debug = state.HeapDebug != 0
+	heap.allocs = int(state.HeapAllocs)
+	heap.frees = int(state.HeapFrees)
+	heap.reallocs = int(state.HeapReallocs)
+	heap.failures = int(state.HeapFailures)
+	heap.inUse = int(state.HeapInUse)
+	heap.peak = int(state.HeapPeak)
+	heap.gc = gc{
+		threshold: int(state.GCThreshold),
+		allocated: int(state.GCAllocated),
+		roots:     roots,
+		stats: GCStats{
+			Collections: int(state.GCCollections),
+			Freed:       int(state.GCFreed),
+			FreedBytes:  int(state.GCFreedBytes),
+			Live:        int(state.GCLive),
+		},
+	}
+
+	if state.ProgramPointer < 0 || state.ProgramPointer >= state.MemorySize || state.FaultHandler >= state.MemorySize {
+		return nil, fmt.Errorf("%w: illegal program pointer or fault handler", ErrSnapshot)
+	}
+
+	if state.InterruptCount < 0 || state.InterruptCount > MaxInterrupts || (state.InterruptCount > 0 &&
+		(state.InterruptVectors < 0 || state.InterruptVectors+state.InterruptCount*int64(IntSize) > state.MemorySize)) {
+		return nil, fmt.Errorf("%w: illegal interrupt vectors", ErrSnapshot)
+	}
+
+	vm = new(VirtualMachine)
+	vm.buildJumpTables(Extension(state.Extensions))
+	vm.memory = memory
+	vm.stack = stack
+	vm.heap = heap
+	vm.programPointer = int(state.ProgramPointer)
+	vm.loadAddress = int(state.LoadAddress)
+	vm.protection = state.Protection != 0
+	vm.maxSteps = int(state.MaxSteps)
+	vm.steps = int(state.Steps)
+	vm.faultHandler = int(state.FaultHandler)
+	vm.floatDivision = FloatDivision(state.FloatDivision)
+	vm.integerOverflow = IntegerOverflow(state.IntegerOverflow)
+	vm.interruptVectors = int(state.InterruptVectors)
+	vm.interruptCount = int(state.InterruptCount)
+	vm.interruptsEnabled = state.InterruptsEnabled != 0
+	vm.pendingInterrupts = state.PendingInterrupts
+
+	return vm, nil
+}
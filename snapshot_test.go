@@ -0,0 +1,414 @@
+package virtualmachine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	p := countingProgram(100)
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Stop half way through a round, with values on the stack
+	err = vm.SetMaxSteps(403)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Run()
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("Expected: instruction budget exhausted, got %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Fork two runs from the same image
+	for i := 0; i < 2; i++ {
+		restored, err := RestoreVirtualMachine(bytes.NewReader(snapshot.Bytes()))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if restored.ProgramPointer() != vm.ProgramPointer() || restored.Steps() != 403 {
+			t.Errorf("Expected pp %04X after 403 steps, got pp %04X after %d", vm.ProgramPointer(), restored.ProgramPointer(), restored.Steps())
+		}
+		if restored.Stack().Pointer() != vm.Stack().Pointer() {
+			t.Errorf("Expected sp %d, got %d", vm.Stack().Pointer(), restored.Stack().Pointer())
+		}
+
+		err = restored.Run()
+		if !errors.Is(err, ErrBudgetExhausted) {
+			t.Fatalf("Expected the budget to be restored, got %v", err)
+		}
+
+		err = restored.SetMaxSteps(0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		err = restored.Run()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		counter, err := restored.Memory().GetInt(p.Size() - TypeInt.Size())
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if counter != 100 || restored.Steps() != 800 {
+			t.Errorf("Expected 100 after 800 steps, got %d after %d", counter, restored.Steps())
+		}
+	}
+}
+
+func TestSnapshotStackState(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Stack = StackAtBottom
+	cfg.LoadAddress = STACK_SIZE
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Underflow blocks the stack
+	p := NewProgram()
+	p.WriteByte(0x0C) // Opcode: pop-byte
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrStackUnderflow) {
+		t.Fatalf("Expected: stack underflow, got %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !restored.Stack().Underflow() || restored.Stack().Overflow() || restored.stack.offset != 0 {
+		t.Errorf("Expected a blocked stack at the bottom of memory")
+	}
+	if restored.ProgramPointer() != STACK_SIZE {
+		t.Errorf("Expected pp %04X, got %04X", STACK_SIZE, restored.ProgramPointer())
+	}
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	image := snapshot.Bytes()
+
+	// Truncated memory
+	_, err = RestoreVirtualMachine(bytes.NewReader(image[:len(image)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected: unexpected EOF, got %v", err)
+	}
+
+	// Not a snapshot
+	corrupt := append([]byte{}, image...)
+	corrupt[0] = 'X'
+	_, err = RestoreVirtualMachine(bytes.NewReader(corrupt))
+	if err == nil || err.Error() != "not a snapshot" {
+		t.Errorf("Expected: not a snapshot, got %v", err)
+	}
+
+	// From the future
+	corrupt = append([]byte{}, image...)
+	corrupt[4] = SnapshotVersion + 1
+	_, err = RestoreVirtualMachine(bytes.NewReader(corrupt))
+	if err == nil {
+		t.Errorf("Expected: unsupported snapshot version")
+	}
+
+	// A program pointer or fault handler outside of memory
+	for _, change := range []func(state *snapshotState){
+		func(state *snapshotState) { state.ProgramPointer = -1 },
+		func(state *snapshotState) { state.ProgramPointer = MEMORY_SIZE },
+		func(state *snapshotState) { state.FaultHandler = MEMORY_SIZE },
+	} {
+		var state snapshotState
+		offset := binary.Size(snapshotHeader{})
+		err = binary.Read(bytes.NewReader(image[offset:]), binary.LittleEndian, &state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		change(&state)
+
+		var patched bytes.Buffer
+		patched.Write(image[:offset])
+		binary.Write(&patched, binary.LittleEndian, &state)
+		patched.Write(image[offset+binary.Size(state):])
+
+		_, err = RestoreVirtualMachine(&patched)
+		if !errors.Is(err, ErrSnapshot) {
+			t.Errorf("Expected: corrupt snapshot, got %v", err)
+		}
+	}
+}
+
+func TestSnapshotHeap(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(42)    // Operant: 42
+	p.WriteByte(0xB8) // Opcode: int-to-string
+	p.WriteByte(0x00) // Opcode: end
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = p.RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if restored.Heap().Start() != vm.Heap().Start() || restored.Heap().End() != vm.Heap().End() ||
+		restored.Heap().Top() != vm.Heap().Top() {
+		t.Errorf("Expected the heap to be restored")
+	}
+
+	value, err := restored.popString()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if value != "42" {
+		t.Errorf("Expected: 42, got %q", value)
+	}
+}
+
+func TestSnapshotInterrupts(t *testing.T) {
+	vm := interruptMachine(t, interruptProgram())
+	_, err := vm.Step()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	vm.RaiseInterrupt(0)
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if restored.PendingInterrupts() != 1 || !restored.InterruptsEnabled() {
+		t.Errorf("Expected interrupt 0 pending and enabled, got %b", restored.PendingInterrupts())
+	}
+
+	err = restored.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	flag, err := restored.Memory().GetByte(testFlag)
+	if err != nil || flag != 7 {
+		t.Errorf("Expected the flag to be 7, got %d (%v)", flag, err)
+	}
+}
+
+func TestSnapshotProtection(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x00) // Opcode: end
+
+	vm := protectedMachine(t)
+	err := vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, address := range []int{0, 9, 10, MEMORY_SIZE - 1} {
+		if restored.Memory().Permissions(address) != vm.Memory().Permissions(address) {
+			t.Errorf("%d: expected %03b, got %03b", address, vm.Memory().Permissions(address),
+				restored.Memory().Permissions(address))
+		}
+	}
+
+	// Loading again protects the new program
+	err = restored.Load(append(p.Value(), 0x00))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if restored.Memory().Permissions(10) != PermRead|PermExec {
+		t.Errorf("Expected the new program to be protected")
+	}
+}
+
+func TestSnapshotHeapDebug(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x91) // Opcode: dup-int
+	p.WriteByte(0xC2) // Opcode: free
+	p.WriteByte(0x00) // Opcode: end
+
+	vm := heapMachine(t, true)
+	err := p.RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected, _ := vm.Heap().Stats()
+	stats, err := restored.Heap().Stats()
+	if err != nil || stats != expected || !restored.Heap().Debug() {
+		t.Errorf("Expected %+v in debug mode, got %+v (%v)", expected, stats, err)
+	}
+
+	// The freed block is still guarded
+	address, err := restored.Stack().PopInt()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = restored.Memory().GetByte(address)
+	if !errors.Is(err, ErrUseAfterFree) {
+		t.Errorf("Expected: use after free, got %v", err)
+	}
+}
+
+func TestSnapshotGC(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x0D) // Opcode: pop-int
+	p.WriteByte(0xC4) // Opcode: gc
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x00) // Opcode: end
+
+	vm := gcMachine(t, 64)
+	err := vm.AddGCRoot(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = p.RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if restored.GCStats() != vm.GCStats() {
+		t.Errorf("Expected %+v, got %+v", vm.GCStats(), restored.GCStats())
+	}
+	gc := restored.Heap().gc
+	if gc.threshold != 64 || gc.allocated != 8 || len(gc.roots) != 1 || gc.roots[0] != 0 {
+		t.Errorf("Unexpected garbage collector %+v", gc)
+	}
+}
+
+func TestSnapshotDevices(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x21)     // Opcode: get-int()
+	p.WriteInt(testTimer) // Operant: timer
+	p.WriteByte(0x00)     // Opcode: end
+
+	vm := deviceMachine(t, "", nil, time.Now)
+	err := vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var snapshot bytes.Buffer
+	err = vm.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	restored, err := RestoreVirtualMachine(&snapshot)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The ranges are kept, but the devices are gone until they are attached again
+	_, err = restored.Memory().GetInt(testTimer)
+	if !errors.Is(err, ErrDevice) {
+		t.Errorf("Expected a device fault before the timer is attached again, got %v", err)
+	}
+	err = restored.Memory().AttachDevice(testTimer, 2*TimerDeviceSize, NewTimerDevice())
+	if !errors.Is(err, ErrDevice) {
+		t.Errorf("Expected a device fault attaching over another range, got %v", err)
+	}
+	err = restored.Memory().AttachDevice(testTimer, TimerDeviceSize, NewTimerDevice())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = restored.Memory().PutInt(testTimer, 60000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = restored.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	now, err := restored.Stack().PopInt()
+	if err != nil || now < 60000 {
+		t.Errorf("Expected the time from the timer, got %d (%v)", now, err)
+	}
+}
@@ -2,8 +2,9 @@ package virtualmachine
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
-	"unsafe"
 
 	"github.com/ttacon/chalk"
 )
@@ -22,13 +23,13 @@ type Stack struct {
 // PushByte puts a byte on the stack
 func (st *Stack) PushByte(value byte) (err error) {
 	if st.overflow || st.underflow {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
-	size := (int)(unsafe.Sizeof(value))
+	size := ByteSize
 	if st.pointer+size > st.size {
 		st.overflow = true
-		return fmt.Errorf("overflow")
+		return st.fault(ErrStackOverflow)
 	}
 
 	err = st.mem.PutByte(st.offset+st.pointer, value)
@@ -43,7 +44,7 @@ func (st *Stack) PushByte(value byte) (err error) {
 // GetByte returns a byte relative to the stack-pointer
 func (st *Stack) GetByte(offset int) (value byte, err error) {
 	if st.overflow || st.underflow {
-		return 0, fmt.Errorf("blocked")
+		return 0, st.fault(ErrStackBlocked)
 	}
 
 	value, err = st.mem.GetByte(st.offset + st.pointer + offset)
@@ -57,7 +58,7 @@ func (st *Stack) GetByte(offset int) (value byte, err error) {
 // PutByte stores a byte relative to the stack-pointer
 func (st *Stack) PutByte(offset int, value byte) (err error) {
 	if st.overflow || st.underflow {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
 	err = st.mem.PutByte(st.offset+st.pointer+offset, value)
@@ -71,13 +72,13 @@ func (st *Stack) PutByte(offset int, value byte) (err error) {
 // PopByte removes a byte from the stack
 func (st *Stack) PopByte() (value byte, err error) {
 	if st.overflow || st.underflow {
-		return 0, fmt.Errorf("blocked")
+		return 0, st.fault(ErrStackBlocked)
 	}
 
-	size := (int)(unsafe.Sizeof(value))
+	size := ByteSize
 	if st.pointer-size < 0 {
 		st.underflow = true
-		return 0, fmt.Errorf("underflow")
+		return 0, st.fault(ErrStackUnderflow)
 	}
 	st.pointer -= size
 
@@ -94,13 +95,13 @@ func (st *Stack) PopByte() (value byte, err error) {
 // PushInt puts an int on the stack
 func (st *Stack) PushInt(value int) (err error) {
 	if st.overflow || st.underflow {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
-	size := (int)(unsafe.Sizeof(value))
+	size := IntSize
 	if st.pointer+size > st.size {
 		st.overflow = true
-		return fmt.Errorf("overflow")
+		return st.fault(ErrStackOverflow)
 	}
 
 	err = st.mem.PutInt(st.offset+st.pointer, value)
@@ -115,7 +116,7 @@ func (st *Stack) PushInt(value int) (err error) {
 // GetInt returns an int relative to the stack-pointer
 func (st *Stack) GetInt(offset int) (value int, err error) {
 	if st.overflow || st.underflow {
-		return 0, fmt.Errorf("blocked")
+		return 0, st.fault(ErrStackBlocked)
 	}
 
 	value, err = st.mem.GetInt(st.offset + st.pointer + offset)
@@ -129,7 +130,7 @@ func (st *Stack) GetInt(offset int) (value int, err error) {
 // PutByte stores a byte relative to the stack-pointer
 func (st *Stack) PutInt(offset int, value int) (err error) {
 	if st.overflow || st.underflow {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
 	err = st.mem.PutInt(st.offset+st.pointer+offset, value)
@@ -143,13 +144,13 @@ func (st *Stack) PutInt(offset int, value int) (err error) {
 // PopInt removes an int from the stack
 func (st *Stack) PopInt() (value int, err error) {
 	if st.overflow || st.underflow {
-		return 0, fmt.Errorf("blocked")
+		return 0, st.fault(ErrStackBlocked)
 	}
 
-	size := (int)(unsafe.Sizeof(value))
+	size := IntSize
 	if st.pointer-size < 0 {
 		st.underflow = true
-		return 0, fmt.Errorf("underflow")
+		return 0, st.fault(ErrStackUnderflow)
 	}
 
 	st.pointer -= size
@@ -161,17 +162,161 @@ func (st *Stack) PopInt() (value int, err error) {
 	return value, nil
 }
 
+// -- Basic stack functions on int16s -------------------------------------------------------------------------------------------
+
+// PushInt16 puts an int16 on the stack
+func (st *Stack) PushInt16(value int16) (err error) {
+	if st.overflow || st.underflow {
+		return st.fault(ErrStackBlocked)
+	}
+
+	size := Int16Size
+	if st.pointer+size > st.size {
+		st.overflow = true
+		return st.fault(ErrStackOverflow)
+	}
+
+	err = st.mem.PutInt16(st.offset+st.pointer, value)
+	if err != nil {
+		return err
+	}
+
+	st.pointer += size
+	return nil
+}
+
+// GetInt16 returns an int16 relative to the stack-pointer
+func (st *Stack) GetInt16(offset int) (value int16, err error) {
+	if st.overflow || st.underflow {
+		return 0, st.fault(ErrStackBlocked)
+	}
+
+	value, err = st.mem.GetInt16(st.offset + st.pointer + offset)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// PutInt16 stores an int16 relative to the stack-pointer
+func (st *Stack) PutInt16(offset int, value int16) (err error) {
+	if st.overflow || st.underflow {
+		return st.fault(ErrStackBlocked)
+	}
+
+	err = st.mem.PutInt16(st.offset+st.pointer+offset, value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PopInt16 removes an int16 from the stack
+func (st *Stack) PopInt16() (value int16, err error) {
+	if st.overflow || st.underflow {
+		return 0, st.fault(ErrStackBlocked)
+	}
+
+	size := Int16Size
+	if st.pointer-size < 0 {
+		st.underflow = true
+		return 0, st.fault(ErrStackUnderflow)
+	}
+
+	st.pointer -= size
+	value, err = st.mem.GetInt16(st.offset + st.pointer)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// -- Basic stack functions on int32s -------------------------------------------------------------------------------------------
+
+// PushInt32 puts an int32 on the stack
+func (st *Stack) PushInt32(value int32) (err error) {
+	if st.overflow || st.underflow {
+		return st.fault(ErrStackBlocked)
+	}
+
+	size := Int32Size
+	if st.pointer+size > st.size {
+		st.overflow = true
+		return st.fault(ErrStackOverflow)
+	}
+
+	err = st.mem.PutInt32(st.offset+st.pointer, value)
+	if err != nil {
+		return err
+	}
+
+	st.pointer += size
+	return nil
+}
+
+// GetInt32 returns an int32 relative to the stack-pointer
+func (st *Stack) GetInt32(offset int) (value int32, err error) {
+	if st.overflow || st.underflow {
+		return 0, st.fault(ErrStackBlocked)
+	}
+
+	value, err = st.mem.GetInt32(st.offset + st.pointer + offset)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// PutInt32 stores an int32 relative to the stack-pointer
+func (st *Stack) PutInt32(offset int, value int32) (err error) {
+	if st.overflow || st.underflow {
+		return st.fault(ErrStackBlocked)
+	}
+
+	err = st.mem.PutInt32(st.offset+st.pointer+offset, value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PopInt32 removes an int32 from the stack
+func (st *Stack) PopInt32() (value int32, err error) {
+	if st.overflow || st.underflow {
+		return 0, st.fault(ErrStackBlocked)
+	}
+
+	size := Int32Size
+	if st.pointer-size < 0 {
+		st.underflow = true
+		return 0, st.fault(ErrStackUnderflow)
+	}
+
+	st.pointer -= size
+	value, err = st.mem.GetInt32(st.offset + st.pointer)
+	if err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
 // -- Basic stack functions on floats -------------------------------------------------------------------------------------------
 
 func (st *Stack) PushFloat(value float64) (err error) {
 	if st.overflow || st.underflow {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
-	size := (int)(unsafe.Sizeof(value))
+	size := FloatSize
 	if st.pointer+size > st.size {
 		st.overflow = true
-		return fmt.Errorf("overflow")
+		return st.fault(ErrStackOverflow)
 	}
 
 	err = st.mem.PutFloat(st.offset+st.pointer, value)
@@ -186,7 +331,7 @@ func (st *Stack) PushFloat(value float64) (err error) {
 // GetFloat returns a float relative to the stack-pointer
 func (st *Stack) GetFloat(offset int) (value float64, err error) {
 	if st.overflow || st.underflow {
-		return 0, fmt.Errorf("blocked")
+		return 0, st.fault(ErrStackBlocked)
 	}
 
 	value, err = st.mem.GetFloat(st.offset + st.pointer + offset)
@@ -200,7 +345,7 @@ func (st *Stack) GetFloat(offset int) (value float64, err error) {
 // PutFloat stores a float relative to the stack-pointer
 func (st *Stack) PutFloat(offset int, value float64) (err error) {
 	if st.overflow || st.underflow {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
 	err = st.mem.PutFloat(st.offset+st.pointer+offset, value)
@@ -213,13 +358,13 @@ func (st *Stack) PutFloat(offset int, value float64) (err error) {
 
 func (st *Stack) PopFloat() (result float64, err error) {
 	if st.overflow || st.underflow {
-		return 0, fmt.Errorf("blocked")
+		return 0, st.fault(ErrStackBlocked)
 	}
 
-	size := (int)(unsafe.Sizeof(result))
+	size := FloatSize
 	if st.pointer-size < 0 {
 		st.underflow = true
-		return 0, fmt.Errorf("underflow")
+		return 0, st.fault(ErrStackUnderflow)
 	}
 
 	st.pointer -= size
@@ -231,9 +376,45 @@ func (st *Stack) PopFloat() (result float64, err error) {
 	return result, nil
 }
 
+// -- Stack functions on raw bytes ----------------------------------------------------------------------------------------------
+
+// Drop removes size bytes from the stack, whatever their type. A negative size raises ErrIllegalOperant and leaves the
+// stack as it is.
+func (st *Stack) Drop(size int) (err error) {
+	if st.overflow || st.underflow {
+		return st.fault(ErrStackBlocked)
+	}
+
+	if size < 0 {
+		err := newVMError(ErrIllegalOperant, size)
+		err.StackPointer = st.pointer
+		return err
+	}
+	if st.pointer-size < 0 {
+		st.underflow = true
+		return st.fault(ErrStackUnderflow)
+	}
+
+	st.pointer -= size
+	return nil
+}
+
+// fault raises kind for the top of the stack
+func (st *Stack) fault(kind error) error {
+	err := newVMError(kind, st.offset+st.pointer)
+	err.StackPointer = st.pointer
+	return err
+}
+
 // -- Support functions part of the stack----------------------------------------------------------------------------------------
 
+// Show displays the content of the stack in hex on standard output
 func (st *Stack) Show() {
+	st.ShowTo(os.Stdout)
+}
+
+// ShowTo displays the content of the stack in hex on w, reading it as it is stored so watches don't see it
+func (st *Stack) ShowTo(w io.Writer) {
 	// chalk styles
 	headerStyle := chalk.White.NewStyle().WithBackground(chalk.Blue).WithTextStyle(chalk.Bold)
 	errorStyle := chalk.White.NewStyle().WithBackground(chalk.Red).WithTextStyle(chalk.Bold)
@@ -253,37 +434,33 @@ func (st *Stack) Show() {
 	lineSpaces := lineLength - len(headerText)
 	headerText = strings.Repeat(" ", lineSpaces/2) + headerText + strings.Repeat(" ", lineSpaces-lineSpaces/2)
 	if st.overflow {
-		fmt.Println(errorStyle.Style(headerText))
+		fmt.Fprintln(w, errorStyle.Style(headerText))
 	} else {
-		fmt.Println(headerStyle.Style(headerText))
+		fmt.Fprintln(w, headerStyle.Style(headerText))
 	}
 
 	// Stack contents
 	for i := 0; i < st.size; i++ {
-		value, err := st.mem.GetByte(st.offset + i)
-		if err != nil {
-			return
-		}
-		cell := fmt.Sprintf("%02X", value)
+		cell := fmt.Sprintf("%02X", st.mem.memory[st.offset+i])
 		if i == st.pointer {
-			fmt.Print(pointerStyle.Style(cell))
+			fmt.Fprint(w, pointerStyle.Style(cell))
 		} else {
-			fmt.Print(defaultStyle.Style(cell))
+			fmt.Fprint(w, defaultStyle.Style(cell))
 		}
-		fmt.Print(defaultStyle.Style(" "))
+		fmt.Fprint(w, defaultStyle.Style(" "))
 		if (i+1)%lineItems == 0 {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 
 	// Empty line at the end
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 func (st *Stack) Check(expectedValue []byte) (err error) {
 	// Stack in error state
 	if st.Overflow() || st.Underflow() {
-		return fmt.Errorf("blocked")
+		return st.fault(ErrStackBlocked)
 	}
 
 	// Retrieve entire current stack
@@ -310,6 +487,11 @@ func (st *Stack) Check(expectedValue []byte) (err error) {
 	return nil
 }
 
+// Pointer returns the current top of the stack, relative to its offset in memory
+func (st *Stack) Pointer() int {
+	return st.pointer
+}
+
 func (st *Stack) Underflow() bool {
 	return st.underflow
 }
@@ -324,13 +506,25 @@ func NewStack(mem *Memory, stackSize int) (st *Stack, err error) {
 	if mem == nil {
 		return nil, fmt.Errorf("missing parameter")
 	}
+
+	return newStackAt(mem, mem.Size()-stackSize, stackSize)
+}
+
+// newStackAt places a stack of stackSize bytes at offset in memory
+func newStackAt(mem *Memory, offset int, stackSize int) (st *Stack, err error) {
+	if mem == nil {
+		return nil, fmt.Errorf("missing parameter")
+	}
 	if stackSize < 0 || stackSize > mem.Size() {
 		return nil, fmt.Errorf("illegal stack size")
 	}
+	if offset < 0 || offset+stackSize > mem.Size() {
+		return nil, fmt.Errorf("illegal stack offset")
+	}
 
 	st = new(Stack)
 	st.mem = mem
-	st.offset = mem.Size() - stackSize
+	st.offset = offset
 	st.size = stackSize
 	st.pointer = 0
 
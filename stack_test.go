@@ -1,9 +1,9 @@
 package virtualmachine
 
 import (
+	"errors"
 	"fmt"
 	"testing"
-	"unsafe"
 )
 
 // -- Support functions ---------------------------------------------------------------------------------------------------------
@@ -11,62 +11,62 @@ import (
 // isBlocked checks if all interface functions are indeed blocked once in error
 func (st *Stack) isBlocked() error {
 	err := st.PushByte(0x20)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PushByte open")
 	}
 
 	_, err = st.GetByte(0)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("GetByte open")
 	}
 
 	err = st.PutByte(0, 0)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PutByte open")
 	}
 
 	_, err = st.PopByte()
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PopByte open")
 	}
 
 	err = st.PushInt(-1)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PushInt open")
 	}
 
 	_, err = st.GetInt(0)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("GetInt open")
 	}
 
 	err = st.PutInt(0, 0)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("GetInt open")
 	}
 
 	_, err = st.PopInt()
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PopInt open")
 	}
 
 	err = st.PushFloat(12.50)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PushFloat open")
 	}
 
 	_, err = st.GetFloat(0)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("GetFloat open")
 	}
 
 	err = st.PutFloat(0, 0.0)
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PutFloat open")
 	}
 
 	_, err = st.PopFloat()
-	if err.Error() != "blocked" {
+	if !errors.Is(err, ErrStackBlocked) {
 		return fmt.Errorf("PopFloat open")
 	}
 
@@ -98,7 +98,7 @@ func TestStackPushByte(t *testing.T) {
 	}
 
 	// Fill the stack
-	size := (int)(unsafe.Sizeof(testValue))
+	size := ByteSize
 	for i := size; i < STACK_SIZE; i++ {
 		err := st.PushByte(testValue)
 		if err != nil {
@@ -185,7 +185,7 @@ func TestStackPushInt(t *testing.T) {
 	}
 
 	// Force overflow
-	size := (int)(unsafe.Sizeof(testValue))
+	size := IntSize
 	for i := size; i < STACK_SIZE; i += size {
 		err = st.PushInt(testValue)
 		if err != nil {
@@ -268,7 +268,7 @@ func TestStackPushFloat(t *testing.T) {
 	}
 
 	// Load it to the max
-	sizeValue := (int)(unsafe.Sizeof(testValue))
+	sizeValue := FloatSize
 	for i := sizeValue; i < STACK_SIZE; i += sizeValue {
 		err = st.PushFloat(testValue)
 		if err != nil {
@@ -320,3 +320,43 @@ func TestStackPopFloat(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestStackDrop(t *testing.T) {
+	st, err := NewStack(NewMemory(MEMORY_SIZE), STACK_SIZE)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	err = st.PushByte(0x20)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	err = st.PushInt(1234)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Drop the int
+	err = st.Drop(IntSize)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	expectedByte := [...]byte{0x20}
+	err = st.Check(expectedByte[:])
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Force underflow
+	err = st.Drop(2)
+	if err == nil {
+		t.Errorf("Expected: underflow")
+	}
+
+	// Should be blocked
+	err = st.isBlocked()
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
@@ -0,0 +1,125 @@
+package virtualmachine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// TraceEvent describes a single executed instruction, end and instructions that fault included
+type TraceEvent struct {
+	ProgramPointer int    `json:"pc"`
+	Opcode         Opcode `json:"opcode"`
+	Mnemonic       string `json:"mnemonic"`
+	Operant        string `json:"operant,omitempty"` // formatted the way the assembler reads it
+	StackBefore    int    `json:"spBefore"`
+	StackAfter     int    `json:"spAfter"`
+	Fault          string `json:"fault,omitempty"` // the fault the instruction raised, empty when it didn't
+}
+
+// String renders the event like the assembler source, with the program and stack pointers around it
+func (e TraceEvent) String() string {
+	text := e.Mnemonic
+	if e.Operant != "" {
+		text += " " + e.Operant
+	}
+
+	line := fmt.Sprintf("%04X  %02X  %-24s sp %d -> %d", e.ProgramPointer, e.Opcode, text, e.StackBefore, e.StackAfter)
+	if e.Fault != "" {
+		line += "  fault: " + e.Fault
+	}
+
+	return line
+}
+
+// Tracer receives an event for every instruction the virtual machine executed
+type Tracer interface {
+	Trace(event TraceEvent)
+}
+
+// -- Sinks ---------------------------------------------------------------------------------------------------------------------
+
+// NopTracer throws the events away, setting it on the virtual machine switches tracing off entirely
+type NopTracer struct{}
+
+func (NopTracer) Trace(event TraceEvent) {}
+
+// WriterTracer writes the events to an io.Writer, as text or as JSON lines. The first write error stops the tracing and
+// is kept in Err.
+type WriterTracer struct {
+	w    io.Writer
+	json bool
+	err  error
+}
+
+// NewTextTracer writes one line of text per event
+func NewTextTracer(w io.Writer) *WriterTracer {
+	return &WriterTracer{w: w}
+}
+
+// NewJSONTracer writes one JSON object per line per event
+func NewJSONTracer(w io.Writer) *WriterTracer {
+	return &WriterTracer{w: w, json: true}
+}
+
+func (t *WriterTracer) Trace(event TraceEvent) {
+	if t.err != nil {
+		return
+	}
+
+	if t.json {
+		t.err = json.NewEncoder(t.w).Encode(event)
+	} else {
+		_, t.err = fmt.Fprintln(t.w, event)
+	}
+}
+
+// Err returns the first error writing the events
+func (t *WriterTracer) Err() error {
+	return t.err
+}
+
+// -- Filtering -----------------------------------------------------------------------------------------------------------------
+
+// TraceFilter selects events on the section of the opcode table (0x40 for the arithmetic, 0xE0 for jumps, 0xFF40 for the
+// arithmetic on the extended page and so on) and on the address of the instruction. Empty sections and a zero To mean
+// everything.
+type TraceFilter struct {
+	Sections []Opcode
+	From     int // first address to trace
+	To       int // first address no longer traced
+}
+
+// Match tells whether the filter lets the event through
+func (f TraceFilter) Match(event TraceEvent) bool {
+	if event.ProgramPointer < f.From || (f.To > 0 && event.ProgramPointer >= f.To) {
+		return false
+	}
+
+	if len(f.Sections) == 0 {
+		return true
+	}
+	for _, section := range f.Sections {
+		if event.Opcode&^0x0F == section&^0x0F {
+			return true
+		}
+	}
+
+	return false
+}
+
+type filterTracer struct {
+	filter TraceFilter
+	next   Tracer
+}
+
+// NewFilterTracer passes the events matching the filter on to next
+func NewFilterTracer(filter TraceFilter, next Tracer) Tracer {
+	return &filterTracer{filter: filter, next: next}
+}
+
+func (t *filterTracer) Trace(event TraceEvent) {
+	if t.filter.Match(event) {
+		t.next.Trace(event)
+	}
+}
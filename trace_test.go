@@ -0,0 +1,162 @@
+package virtualmachine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// tracedProgram pushes two ints, adds them and jumps to the end
+func tracedProgram() *Program {
+	p := NewProgram()
+	p.WriteByte(0x09)                     // Opcode: push-int
+	p.WriteInt(4)                         // Operant: 4
+	p.WriteByte(0x09)                     // Opcode: push-int
+	p.WriteInt(-6)                        // Operant: -6
+	p.WriteByte(0x41)                     // Opcode: add-int
+	p.WriteByte(0xE1)                     // Opcode: jmp()
+	p.WriteInt(p.Size() + TypeInt.Size()) // Operant: the end
+	p.WriteByte(0x00)                     // Opcode: end
+
+	return p
+}
+
+func TestTextTracer(t *testing.T) {
+	var out bytes.Buffer
+	tracer := NewTextTracer(&out)
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	vm.SetTracer(tracer)
+
+	err = tracedProgram().RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	n := 1 + TypeInt.Size()
+	expected := []string{
+		TraceEvent{0, 0x09, "push-int", "4", 0, 8, ""}.String(),
+		TraceEvent{n, 0x09, "push-int", "-6", 8, 16, ""}.String(),
+		TraceEvent{2 * n, 0x41, "add-int", "", 16, 8, ""}.String(),
+		TraceEvent{2*n + 1, 0xE1, "jmp", "(28)", 8, 8, ""}.String(),
+		TraceEvent{3*n + 1, 0x00, "end", "", 8, 8, ""}.String(),
+	}
+	if out.String() != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), out.String())
+	}
+	if tracer.Err() != nil {
+		t.Errorf(tracer.Err().Error())
+	}
+}
+
+func TestJSONTracer(t *testing.T) {
+	var out bytes.Buffer
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	vm.SetTracer(NewJSONTracer(&out))
+
+	err = tracedProgram().RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines, got %d", len(lines))
+	}
+
+	var event TraceEvent
+	err = json.Unmarshal([]byte(lines[1]), &event)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if event.Mnemonic != "push-int" || event.Operant != "-6" || event.StackBefore != 8 || event.StackAfter != 16 {
+		t.Errorf("Unexpected event %s", lines[1])
+	}
+}
+
+func TestTraceFault(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(0)     // Operant: 0
+	p.WriteByte(0x4D) // Opcode: div-int
+	p.WriteByte(0x00) // Opcode: end
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var events []TraceEvent
+	vm.SetTracer(countingTracer(func(event TraceEvent) { events = append(events, event) }))
+
+	// The tracer doesn't show up as reads of the operants, only the opcodes and the operants themselves are read
+	reads := 0
+	vm.Memory().SetWatch(func(address int, size int, write bool) {
+		if !write && address < p.Size() {
+			reads++
+		}
+	})
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Fatalf("Expected: division by zero, got %v", err)
+	}
+
+	if len(events) != 3 || events[2].Mnemonic != "div-int" || events[2].Fault != err.Error() {
+		t.Errorf("Expected the faulting div-int to be traced, got %v", events)
+	}
+	if reads != 5 {
+		t.Errorf("Expected 5 reads of the program, got %d", reads)
+	}
+}
+
+func TestFilterTracer(t *testing.T) {
+	var out bytes.Buffer
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Only the arithmetic and the jumps, from the second instruction on
+	vm.SetTracer(NewFilterTracer(TraceFilter{Sections: []Opcode{0x40, 0xE0}, From: 1}, NewTextTracer(&out)))
+
+	err = tracedProgram().RunOn(vm, nil, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "add-int") || !strings.Contains(lines[1], "jmp") {
+		t.Errorf("Unexpected trace:\n%s", out.String())
+	}
+
+	// An address range that excludes everything
+	filter := TraceFilter{From: 0, To: 1}
+	if filter.Match(TraceEvent{ProgramPointer: 1}) {
+		t.Errorf("Expected address 1 to be filtered out")
+	}
+}
+
+func TestNopTracer(t *testing.T) {
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vm.SetTracer(NopTracer{})
+	if vm.tracer != nil {
+		t.Errorf("Expected tracing to be off")
+	}
+}
@@ -1,197 +1,474 @@
 package virtualmachine
 
 import (
-	"bytes"
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"os"
 )
 
-// Operation maps a processor instruction as a function pointer
-type Operation func() error
+// cancelInterval is the number of instructions RunContext executes between checks of its context
+const cancelInterval = 1024
+
+// Operation executes a processor instruction on the virtual machine
+type Operation func(vm *VirtualMachine) error
 
 // Virtual Machine models an entirely stack based processor.
 type VirtualMachine struct {
-	jumpTable [256]Operation
-	stack     *Stack
-	memory    *Memory
+	pendingInterrupts uint64 // a bit per raised interrupt, first in the struct so atomic access is aligned on 32-bit hosts
+
+	jumpTable     [256]*Instruction
+	extendedTable [256]*Instruction // instructions on the extended page, behind ExtendedPage
+	extensions    Extension         // extensions in the jump tables on top of the base set
+	stack         *Stack
+	memory        *Memory
+	heap          *Heap
 
 	programPointer int
 
-	logBuffer bytes.Buffer
-	logFile   *log.Logger
+	loadAddress int  // where Load puts the program and execution starts
+	protection  bool // Load protects the program and the memory around it
+	maxSteps    int  // instructions to execute before ErrBudgetExhausted, 0 for no limit
+	steps       int  // instructions executed so far
+
+	faultHandler    int             // address division faults jump to, negative to stop the program
+	floatDivision   FloatDivision   // what a float division by zero does
+	integerOverflow IntegerOverflow // what an int add, sub or mul that doesn't fit does
+
+	hostFuncs map[int]HostFunc // called by syscall
+
+	interruptVectors  int  // address of the interrupt vector table
+	interruptCount    int  // number of interrupts in the vector table
+	interruptsEnabled bool // if pending interrupts are taken
+
+	tracer Tracer
+	input  *bufio.Reader // nil when there is no input
+	output io.Writer     // nil when output is thrown away
 }
 
-// -- LOGGING SECTION --------------------------------------------------------------------------------------
-// Initial version alway logs and simply to a buffer. Later we'll add more options
+// -- TRACING SECTION --------------------------------------------------------------------------------------
+// Tracing is off unless a tracer is set, when off it costs nothing but a nil check per instruction
 
-func (vm *VirtualMachine) initLogging() error {
-	vm.logFile = log.New(&(vm.logBuffer), "", log.LstdFlags|log.Ltime|log.Lmicroseconds|log.LUTC)
-	return nil
+// SetTracer sends an event for every executed instruction to tracer, nil (or a NopTracer) switches tracing off
+func (vm *VirtualMachine) SetTracer(tracer Tracer) {
+	if _, nop := tracer.(NopTracer); nop {
+		tracer = nil
+	}
+
+	vm.tracer = tracer
 }
 
-func (vm *VirtualMachine) addLog(format string, v ...interface{}) error {
-	if vm.logFile != nil {
-		vm.logFile.Printf(format, v...)
+// -- CONSOLE SECTION --------------------------------------------------------------------------------------
+// The input/output instructions read from and write to the console, without one the input is at its end and the output is
+// thrown away
+
+// SetInput makes the read-... instructions read from r, nil disconnects the input
+func (vm *VirtualMachine) SetInput(r io.Reader) {
+	if r == nil {
+		vm.input = nil
+		return
 	}
+
+	vm.input = bufio.NewReader(r)
+}
+
+// SetOutput makes the print-... instructions write to w, nil throws the output away
+func (vm *VirtualMachine) SetOutput(w io.Writer) {
+	vm.output = w
+}
+
+// -- FAULT SECTION ----------------------------------------------------------------------------------------
+// Faults of the arithmetic, conversions, string indexes and input either stop the program or jump to a handler in the
+// program
+
+// FloatDivision decides what a float division by zero does
+type FloatDivision int
+
+const (
+	FloatDivisionIEEE FloatDivision = iota // results in +Inf, -Inf or NaN
+	FloatDivisionTrap                      // raises ErrDivisionByZero like the byte and int divisions
+)
+
+// SetFaultHandler makes the faults a program can recover from jump to address, as if the faulting instruction called it:
+// the operants are gone, the address of the next instruction is pushed and the FaultCode of the kind is pushed as an int
+// on top of it. The handler pops the code and can ret to carry on. These are ErrDivisionByZero, ErrIntegerOverflow,
+// ErrInvalidConversion, ErrIndexOutOfRange and ErrEndOfInput; the others always stop the program. A negative address
+// restores the default of stopping the program with a VMError.
+func (vm *VirtualMachine) SetFaultHandler(address int) error {
+	if address >= vm.memory.Size() {
+		return newVMError(ErrIllegalAddress, address)
+	}
+
+	vm.faultHandler = address
 	return nil
 }
 
-func (vm *VirtualMachine) showLog() error {
-	if vm.logFile != nil {
-		fmt.Print(&vm.logBuffer)
+// FaultHandler returns the address faults jump to, negative when they stop the program
+func (vm *VirtualMachine) FaultHandler() int {
+	return vm.faultHandler
+}
+
+// SetFloatDivision decides what a float division by zero does
+func (vm *VirtualMachine) SetFloatDivision(policy FloatDivision) {
+	vm.floatDivision = policy
+}
+
+// SetIntegerOverflow decides what an int add, sub or mul does when the result doesn't fit
+func (vm *VirtualMachine) SetIntegerOverflow(policy IntegerOverflow) {
+	vm.integerOverflow = policy
+}
+
+// fault raises kind for the instruction at the program pointer, next is where the program would have continued
+func (vm *VirtualMachine) fault(kind error, next int) error {
+	if vm.faultHandler < 0 {
+		return newVMError(kind, -1)
 	}
+
+	err := vm.stack.PushInt(next)
+	if err != nil {
+		return err
+	}
+	err = vm.stack.PushInt(FaultCode(kind))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer = vm.faultHandler
 	return nil
 }
 
+// locate ties a fault raised by an instruction, or by Memory or Stack on its behalf, to that instruction
+func (vm *VirtualMachine) locate(err error, opcode Opcode) error {
+	var vmErr *VMError
+	if errors.As(err, &vmErr) {
+		vmErr.ProgramPointer = vm.programPointer
+		vmErr.Opcode = opcode
+		vmErr.StackPointer = vm.stack.pointer
+	}
+
+	return err
+}
+
 // -- VIRTUAL MACHINE SECTION ------------------------------------------------------------------------------
 
 func (vm *VirtualMachine) ShowStack() {
+	vm.ShowStackTo(os.Stdout)
+}
+
+func (vm *VirtualMachine) ShowMemory() {
+	vm.ShowMemoryTo(os.Stdout)
+}
+
+// ShowStackTo displays the stack on w
+func (vm *VirtualMachine) ShowStackTo(w io.Writer) {
 	if vm.stack != nil {
-		vm.stack.Show()
+		vm.stack.ShowTo(w)
 	}
 }
 
-func (vm *VirtualMachine) ShowMemory() {
+// ShowMemoryTo displays the memory on w, with the program pointer underlined
+func (vm *VirtualMachine) ShowMemoryTo(w io.Writer) {
 	if vm.memory != nil {
-		vm.memory.Show(vm.programPointer)
+		vm.memory.ShowTo(w, vm.programPointer)
+	}
+}
+
+// ProgramPointer returns the address of the next instruction to execute
+func (vm *VirtualMachine) ProgramPointer() int {
+	return vm.programPointer
+}
+
+// Memory gives access to the memory of the virtual machine, for tools like debuggers
+func (vm *VirtualMachine) Memory() *Memory {
+	return vm.memory
+}
+
+// Stack gives access to the stack of the virtual machine, for tools like debuggers
+func (vm *VirtualMachine) Stack() *Stack {
+	return vm.stack
+}
+
+// Heap gives access to the heap of the virtual machine, for tools like debuggers
+func (vm *VirtualMachine) Heap() *Heap {
+	return vm.heap
+}
+
+// SetMaxSteps limits the total number of instructions Step and Run execute, 0 for no limit. Raising it after
+// ErrBudgetExhausted lets the program carry on.
+func (vm *VirtualMachine) SetMaxSteps(maxSteps int) error {
+	if maxSteps < 0 {
+		return fmt.Errorf("illegal maximum number of steps")
 	}
+
+	vm.maxSteps = maxSteps
+	return nil
+}
+
+// Steps returns the number of instructions executed so far
+func (vm *VirtualMachine) Steps() int {
+	return vm.steps
 }
 
+// Load puts the program at the load address and points the program pointer at it, the heap starts empty right after it
 func (vm *VirtualMachine) Load(program []byte) error {
+	if vm.protection {
+		vm.memory.ClearProtection()
+	}
+
 	for i, v := range program {
-		err := vm.memory.PutByte(i, v)
+		err := vm.memory.PutByte(vm.loadAddress+i, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	if vm.protection {
+		err := vm.protect(len(program))
 		if err != nil {
 			return err
 		}
 	}
+
+	vm.programPointer = vm.loadAddress
+	vm.placeHeap(vm.loadAddress + len(program))
 	return nil
 }
 
+// protect makes the program of size bytes at the load address read-only and executable, and everything else, the stack
+// included, read/write but not executable
+func (vm *VirtualMachine) protect(size int) error {
+	err := vm.memory.Protect(0, vm.memory.Size(), PermRead|PermWrite)
+	if err != nil || size == 0 {
+		return err
+	}
+
+	return vm.memory.Protect(vm.loadAddress, size, PermRead|PermExec)
+}
+
+// placeHeap puts an empty heap from start up to the stack, or up to the end of memory when the stack is below start
+func (vm *VirtualMachine) placeHeap(start int) {
+	end := vm.memory.Size()
+	if vm.stack.offset >= start {
+		end = vm.stack.offset
+	}
+
+	heap := newHeap(vm.memory, vm.stack, start, end)
+	if vm.heap != nil {
+		vm.memory.unguard(vm.heap.start, vm.heap.end)
+		heap.debug = vm.heap.debug
+		heap.gc.threshold = vm.heap.gc.threshold
+		heap.gc.roots = vm.heap.gc.roots
+	}
+
+	vm.heap = heap
+}
+
+// traceEvent describes the instruction at the program pointer before it executes. The operant is read as it is stored, so
+// watches and devices don't see the tracer; one running past the end of memory is left out.
+func (vm *VirtualMachine) traceEvent(in *Instruction) TraceEvent {
+	event := TraceEvent{
+		ProgramPointer: vm.programPointer,
+		Opcode:         in.Opcode,
+		Mnemonic:       in.Mnemonic,
+		StackBefore:    vm.stack.pointer,
+	}
+
+	operant, err := vm.memory.Peek(vm.programPointer+in.Opcode.Size(), in.Operant.Size())
+	if err == nil {
+		event.Operant = in.Operant.Format(operant)
+	}
+
+	return event
+}
+
+// trace completes event after its instruction executed, with the fault it raised if any, and hands it to the tracer
+func (vm *VirtualMachine) trace(event TraceEvent, err error) {
+	event.StackAfter = vm.stack.pointer
+	if err != nil {
+		event.Fault = err.Error()
+	}
+
+	vm.tracer.Trace(event)
+}
+
+// fetch reads the opcode at the program pointer and finds its instruction, nil when it is unknown or not enabled
+func (vm *VirtualMachine) fetch() (Opcode, *Instruction, error) {
+	err := vm.memory.permit(vm.programPointer, ByteSize, PermExec)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	first, err := vm.memory.GetByte(vm.programPointer)
+	if err != nil {
+		return 0, nil, err
+	}
+	if first != ExtendedPage {
+		return Opcode(first), vm.jumpTable[first], nil
+	}
+
+	second, err := vm.memory.GetByte(vm.programPointer + 1)
+	if err != nil {
+		return Opcode(first), nil, err
+	}
+
+	return Opcode(first)<<8 | Opcode(second), vm.extendedTable[second], nil
+}
+
 // Step executes a single instruction and returns if we are ended
 func (vm *VirtualMachine) Step() (bool, error) {
+	// Take a pending interrupt, as if the next instruction was a call of its handler
+	if vm.interruptsEnabled && (vm.maxSteps == 0 || vm.steps < vm.maxSteps) {
+		err := vm.interrupt()
+		if err != nil {
+			opcode, _, _ := vm.fetch()
+			return true, vm.locate(err, opcode)
+		}
+	}
+
 	// Get operation
-	opCode, err := vm.memory.GetByte(vm.programPointer)
+	opcode, in, err := vm.fetch()
 	if err != nil {
-		return true, err
+		return true, vm.locate(err, opcode)
 	}
 
-	// Check operation
-	if opCode == 0x00 {
+	// Check operation, the budget is checked before anything changes so the program can be resumed
+	if opcode == 0x00 {
+		if vm.tracer != nil {
+			vm.trace(vm.traceEvent(LookupOpcode(opcode)), nil)
+		}
 		return true, nil
 	}
-	if vm.jumpTable[opCode] == nil {
-		return true, fmt.Errorf("opcode %0x unknown", opCode)
+	if vm.maxSteps > 0 && vm.steps >= vm.maxSteps {
+		return true, vm.locate(newVMError(ErrBudgetExhausted, -1), opcode)
+	}
+	if in == nil {
+		return true, vm.locate(newVMError(ErrUnknownOpcode, -1), opcode)
 	}
 
-	// Execute operation
-	err = vm.jumpTable[opCode]()
+	// Execute operation, when tracing the event is picked up front as the program pointer moves. A faulting instruction is
+	// traced with its fault.
+	var event TraceEvent
+	if vm.tracer != nil {
+		event = vm.traceEvent(in)
+	}
+
+	vm.steps++
+	err = in.Handler(vm)
 	if err != nil {
-		return true, err
+		err = vm.locate(err, opcode)
 	}
 
-	return false, nil
+	if vm.tracer != nil {
+		vm.trace(event, err)
+	}
+	return err != nil, err
 }
 
 // main loop of the virtual machine
 func (vm *VirtualMachine) Run() error {
+	return vm.RunContext(context.Background())
+}
 
-	// Start the logbook, later only in error mode
-	err := vm.initLogging()
-	if err != nil {
-		return err
-	}
-	vm.addLog("<start program>")
+// RunContext runs the program until it ends, faults, runs out of budget or ctx is done. Cancellation is checked every
+// cancelInterval instructions and raises ErrCancelled with the reason of ctx as its cause. The program pointer is left at
+// the next instruction, so after a budget or cancellation fault a later call carries on where this one stopped.
+func (vm *VirtualMachine) RunContext(ctx context.Context) error {
+	done := ctx.Done()
+
+	for i := 0; ; i++ {
+		if done != nil && i%cancelInterval == 0 {
+			select {
+			case <-done:
+				err := newVMError(ErrCancelled, -1)
+				err.Cause = ctx.Err()
+				opcode, _, _ := vm.fetch()
+				return vm.locate(err, opcode)
+			default:
+			}
+		}
 
-	// Keep stepping until done
-	atEnd, err := vm.Step()
-	for !atEnd && err == nil {
-		atEnd, err = vm.Step()
+		atEnd, err := vm.Step()
+		if atEnd || err != nil {
+			return err
+		}
 	}
+}
 
-	vm.addLog("<end program>")
-	vm.showLog()
-	return err
+// buildJumpTables enables the base instruction set and the instructions of extensions, end (0x00) has no handler and is
+// handled by Step
+func (vm *VirtualMachine) buildJumpTables(extensions Extension) {
+	vm.extensions = extensions
+	vm.jumpTable = [256]*Instruction{}
+	vm.extendedTable = [256]*Instruction{}
+
+	for i := range InstructionSet {
+		in := &InstructionSet[i]
+		if in.Handler == nil || in.Extension&extensions != in.Extension {
+			continue
+		}
+
+		if in.Opcode > 0xFF {
+			vm.extendedTable[in.Opcode&0xFF] = in
+		} else {
+			vm.jumpTable[in.Opcode] = in
+		}
+	}
 }
 
 // -- Companion functions -------------------------------------------------------------------------------------------------------
 
+// NewVirtualMachine creates a virtual machine with the default configuration
 func NewVirtualMachine(memorySize int, stackSize int) (vm *VirtualMachine, err error) {
+	return NewVirtualMachineWithConfig(DefaultConfig(memorySize, stackSize))
+}
+
+// NewVirtualMachineWithConfig creates a virtual machine with the stack, limits, policies and instructions of cfg
+func NewVirtualMachineWithConfig(cfg Config) (vm *VirtualMachine, err error) {
+	if cfg.MemorySize < 0 {
+		return nil, fmt.Errorf("illegal memory size")
+	}
+	if cfg.MaxSteps < 0 {
+		return nil, fmt.Errorf("illegal maximum number of steps")
+	}
+
 	vm = new(VirtualMachine)
+	vm.faultHandler = -1
+	vm.maxSteps = cfg.MaxSteps
+	vm.floatDivision = cfg.FloatDivision
+	vm.integerOverflow = cfg.IntegerOverflow
+	vm.SetTracer(cfg.Tracer)
+	vm.SetInput(cfg.Input)
+	vm.SetOutput(cfg.Output)
 
-	// Build the jumpTable
-	vm.jumpTable[0x00] = nil // End
-	vm.jumpTable[0x08] = vm.operationPushByte
-	vm.jumpTable[0x09] = vm.operationPushInt
-	vm.jumpTable[0x0A] = vm.operationPushFloat
-	vm.jumpTable[0x0C] = vm.operationPopByte
-	vm.jumpTable[0x0D] = vm.operationPopInt
-	vm.jumpTable[0x0E] = vm.operationPopFloat
-	vm.jumpTable[0x10] = vm.operationGetByte
-	vm.jumpTable[0x11] = vm.operationGetInt
-	vm.jumpTable[0x12] = vm.operationGetFloat
-	vm.jumpTable[0x18] = vm.operationPutByte
-	vm.jumpTable[0x19] = vm.operationPutInt
-	vm.jumpTable[0x1A] = vm.operationPutFloat
-	vm.jumpTable[0x20] = vm.operationGetByteAddress
-	vm.jumpTable[0x21] = vm.operationGetIntAddress
-	vm.jumpTable[0x22] = vm.operationGetFloatAddress
-	vm.jumpTable[0x28] = vm.operationPutByteAddress
-	vm.jumpTable[0x29] = vm.operationPutIntAddress
-	vm.jumpTable[0x2A] = vm.operationPutFloatAddress
-	vm.jumpTable[0x30] = vm.operationGetByteStack
-	vm.jumpTable[0x31] = vm.operationGetIntStack
-	vm.jumpTable[0x32] = vm.operationGetFloatStack
-	vm.jumpTable[0x38] = vm.operationPutByteStack
-	vm.jumpTable[0x39] = vm.operationPutIntStack
-	vm.jumpTable[0x3A] = vm.operationPutFloatStack
-	vm.jumpTable[0x40] = vm.operationAddByte
-	vm.jumpTable[0x41] = vm.operationAddInt
-	vm.jumpTable[0x42] = vm.operationAddFloat
-	vm.jumpTable[0x44] = vm.operationSubByte
-	vm.jumpTable[0x45] = vm.operationSubInt
-	vm.jumpTable[0x46] = vm.operationSubFloat
-	vm.jumpTable[0x48] = vm.operationMulByte
-	vm.jumpTable[0x49] = vm.operationMulInt
-	vm.jumpTable[0x4A] = vm.operationMulFloat
-	vm.jumpTable[0x4C] = vm.operationDivByte
-	vm.jumpTable[0x4D] = vm.operationDivInt
-	vm.jumpTable[0x4E] = vm.operationDivFloat
-	vm.jumpTable[0x60] = vm.operationEqualByte
-	vm.jumpTable[0x61] = vm.operationEqualInt
-	vm.jumpTable[0x62] = vm.operationEqualFloat
-	vm.jumpTable[0x64] = vm.operationUnequalByte
-	vm.jumpTable[0x65] = vm.operationUnequalInt
-	vm.jumpTable[0x66] = vm.operationUnequalFloat
-	vm.jumpTable[0x68] = vm.operationGreaterByte
-	vm.jumpTable[0x69] = vm.operationGreaterInt
-	vm.jumpTable[0x6A] = vm.operationGreaterFloat
-	vm.jumpTable[0x6C] = vm.operationSmallerByte
-	vm.jumpTable[0x6D] = vm.operationSmallerInt
-	vm.jumpTable[0x6E] = vm.operationSmallerFloat
-	vm.jumpTable[0x70] = vm.operationAndByte
-	vm.jumpTable[0x71] = vm.operationOrByte
-	vm.jumpTable[0x72] = vm.operationNotByte
-	vm.jumpTable[0x73] = vm.operationXorByte
-	vm.jumpTable[0xE0] = vm.operationRet
-	vm.jumpTable[0xE1] = vm.operationJmp
-	vm.jumpTable[0xE4] = vm.operationJmpzByte
-	vm.jumpTable[0xE5] = vm.operationJmpzInt
-	vm.jumpTable[0xE6] = vm.operationJmpzFloat
-	vm.jumpTable[0xE8] = vm.operationJmpzByteAddress
-	vm.jumpTable[0xE9] = vm.operationJmpzIntAddress
-	vm.jumpTable[0xEA] = vm.operationJmpzFloatAddress
-	vm.jumpTable[0xEC] = vm.operationJmpnzByte
-	vm.jumpTable[0xED] = vm.operationJmpnzInt
-	vm.jumpTable[0xEE] = vm.operationJmpnzFloat
-	vm.jumpTable[0xF0] = vm.operationJmpnzByteAddress
-	vm.jumpTable[0xF1] = vm.operationJmpnzIntAddress
-	vm.jumpTable[0xF2] = vm.operationJmpnzFloatAddress
-	vm.jumpTable[0xF8] = vm.operationCall
-	vm.jumpTable[0xF9] = vm.operationCallAddress
+	vm.buildJumpTables(cfg.Extensions)
 
 	// Build the resources
-	vm.memory = NewMemory(memorySize)
-	vm.stack, err = NewStack(vm.memory, stackSize)
+	vm.memory = NewMemory(cfg.MemorySize)
+	switch cfg.Stack {
+	case StackAtTop:
+		vm.stack, err = newStackAt(vm.memory, cfg.MemorySize-cfg.StackSize, cfg.StackSize)
+	case StackAtBottom:
+		vm.stack, err = newStackAt(vm.memory, 0, cfg.StackSize)
+	default:
+		err = fmt.Errorf("illegal stack placement")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Programs can't be loaded into the stack
+	if cfg.LoadAddress < 0 || cfg.LoadAddress >= cfg.MemorySize ||
+		(cfg.LoadAddress >= vm.stack.offset && cfg.LoadAddress < vm.stack.offset+vm.stack.size) {
+		return nil, fmt.Errorf("illegal load address")
+	}
+	vm.loadAddress = cfg.LoadAddress
+	vm.protection = cfg.Protection
+	vm.programPointer = cfg.LoadAddress
+	vm.placeHeap(cfg.LoadAddress)
+	vm.heap.debug = cfg.HeapDebug
+	err = vm.SetGCThreshold(cfg.GCThreshold)
 	if err != nil {
 		return nil, err
 	}
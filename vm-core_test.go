@@ -1,8 +1,11 @@
 package virtualmachine
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"unsafe"
+	"testing"
+	"time"
 )
 
 // -- Reasonable values for testing ---------------------------------------------------------------------------------------------
@@ -19,34 +22,81 @@ type Buffer struct {
 }
 
 func (b *Buffer) WriteByte(value byte) (err error) {
-	if b.len+(int)(unsafe.Sizeof(value)) > len(b.bytes) {
+	if b.len+ByteSize > len(b.bytes) {
 		return fmt.Errorf("buffer overflow")
 	}
 
-	*(*byte)(unsafe.Pointer(&b.bytes[b.len])) = value
-	b.len += (int)(unsafe.Sizeof(value))
+	b.bytes[b.len] = value
+	b.len += ByteSize
 
 	return nil
 }
 
 func (b *Buffer) WriteInt(value int) (err error) {
-	if b.len+(int)(unsafe.Sizeof(value)) > len(b.bytes) {
+	if b.len+IntSize > len(b.bytes) {
 		return fmt.Errorf("buffer overflow")
 	}
 
-	*(*int)(unsafe.Pointer(&b.bytes[b.len])) = value
-	b.len += (int)(unsafe.Sizeof(value))
+	encodeInt(b.bytes[b.len:], value)
+	b.len += IntSize
+
+	return nil
+}
+
+func (b *Buffer) WriteInt16(value int16) (err error) {
+	if b.len+Int16Size > len(b.bytes) {
+		return fmt.Errorf("buffer overflow")
+	}
+
+	encodeInt16(b.bytes[b.len:], value)
+	b.len += Int16Size
+
+	return nil
+}
+
+func (b *Buffer) WriteInt32(value int32) (err error) {
+	if b.len+Int32Size > len(b.bytes) {
+		return fmt.Errorf("buffer overflow")
+	}
+
+	encodeInt32(b.bytes[b.len:], value)
+	b.len += Int32Size
+
+	return nil
+}
+
+// WriteOpcode writes an opcode, two bytes for the extended page
+func (b *Buffer) WriteOpcode(opcode Opcode) (err error) {
+	for _, value := range opcode.Bytes() {
+		err = b.WriteByte(value)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (b *Buffer) WriteFloat(value float64) (err error) {
-	if b.len+(int)(unsafe.Sizeof(value)) > len(b.bytes) {
+	if b.len+FloatSize > len(b.bytes) {
+		return fmt.Errorf("buffer overflow")
+	}
+
+	encodeFloat(b.bytes[b.len:], value)
+	b.len += FloatSize
+
+	return nil
+}
+
+// WriteString writes a string the way push-string expects it: its length followed by its bytes
+func (b *Buffer) WriteString(value string) (err error) {
+	if b.len+IntSize+len(value) > len(b.bytes) {
 		return fmt.Errorf("buffer overflow")
 	}
 
-	*(*float64)(unsafe.Pointer(&b.bytes[b.len])) = value
-	b.len += (int)(unsafe.Sizeof(value))
+	encodeInt(b.bytes[b.len:], len(value))
+	copy(b.bytes[b.len+IntSize:], value)
+	b.len += IntSize + len(value)
 
 	return nil
 }
@@ -84,6 +134,11 @@ func (p *Program) Run(expectedStack *Buffer, expectedMemory *Buffer) (err error)
 		return err
 	}
 
+	return p.RunOn(vm, expectedStack, expectedMemory)
+}
+
+// RunOn runs the program on a virtual machine prepared by the test
+func (p *Program) RunOn(vm *VirtualMachine, expectedStack *Buffer, expectedMemory *Buffer) (err error) {
 	err = vm.Load(p.bytes[:p.len])
 	if err != nil {
 		return err
@@ -114,3 +169,118 @@ func (p *Program) Run(expectedStack *Buffer, expectedMemory *Buffer) (err error)
 func NewProgram() *Program {
 	return new(Program)
 }
+
+// countingProgram adds 1 to the int at its end until it reaches limit, in 8 instructions per round
+func countingProgram(limit int) *Program {
+	n := 1 + TypeInt.Size()
+	counter := 6*n + 3
+
+	p := NewProgram()
+	p.WriteByte(0x21)   // Opcode: get-int()
+	p.WriteInt(counter) // Operant: counter
+	p.WriteByte(0x09)   // Opcode: push-int
+	p.WriteInt(1)       // Operant: 1
+	p.WriteByte(0x41)   // Opcode: add-int
+	p.WriteByte(0x29)   // Opcode: put-int()
+	p.WriteInt(counter) // Operant: counter
+	p.WriteByte(0x21)   // Opcode: get-int()
+	p.WriteInt(counter) // Operant: counter
+	p.WriteByte(0x09)   // Opcode: push-int
+	p.WriteInt(limit)   // Operant: limit
+	p.WriteByte(0x45)   // Opcode: sub-int
+	p.WriteByte(0xF1)   // Opcode: jmpnz-int()
+	p.WriteInt(0)       // Operant: 0
+	p.WriteByte(0x00)   // Opcode: end
+	p.WriteInt(0)       // Data: counter
+
+	return p
+}
+
+func TestRunContextTimeout(t *testing.T) {
+	// Loops forever
+	p := NewProgram()
+	p.WriteByte(0xE1) // Opcode: jmp()
+	p.WriteInt(0)     // Operant: 0
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = vm.RunContext(ctx)
+	if !errors.Is(err, ErrCancelled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected: cancelled by the deadline, got %v", err)
+	}
+
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || vmErr.ProgramPointer != 0 || vmErr.Opcode != 0xE1 {
+		t.Errorf("Unexpected fault %v", err)
+	}
+}
+
+func TestRunContextResume(t *testing.T) {
+	p := countingProgram(100)
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.Load(p.Value())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// A cancelled context stops before the first instruction
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = vm.RunContext(ctx)
+	if !errors.Is(err, ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected: cancelled, got %v", err)
+	}
+	if vm.ProgramPointer() != 0 || vm.Steps() != 0 {
+		t.Errorf("Expected to be untouched, got pp %04X after %d steps", vm.ProgramPointer(), vm.Steps())
+	}
+
+	// Run out of budget half way, then carry on
+	err = vm.SetMaxSteps(150)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = vm.Run()
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("Expected: instruction budget exhausted, got %v", err)
+	}
+	if vm.Steps() != 150 {
+		t.Errorf("Expected 150 steps, got %d", vm.Steps())
+	}
+
+	err = vm.SetMaxSteps(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = vm.Run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vm.Steps() != 800 {
+		t.Errorf("Expected 800 steps, got %d", vm.Steps())
+	}
+
+	counter, err := vm.Memory().GetInt(p.Size() - TypeInt.Size())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if counter != 100 {
+		t.Errorf("Expected 100, got %d", counter)
+	}
+}
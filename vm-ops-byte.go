@@ -1,7 +1,5 @@
 package virtualmachine
 
-import "unsafe"
-
 // operationPushByte takes the following bytes and pushes it as a byte
 func (vm *VirtualMachine) operationPushByte() (err error) {
 	operant, err := vm.memory.GetByte(vm.programPointer + 1)
@@ -15,8 +13,6 @@ func (vm *VirtualMachine) operationPushByte() (err error) {
 	}
 
 	vm.programPointer += 2
-
-	vm.addLog("push-byte %d", operant)
 	return nil
 }
 
@@ -28,8 +24,6 @@ func (vm *VirtualMachine) operationPopByte() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("pop-byte")
 	return nil
 }
 
@@ -51,8 +45,6 @@ func (vm *VirtualMachine) operationGetByte() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("get-byte")
 	return nil
 }
 
@@ -73,9 +65,7 @@ func (vm *VirtualMachine) operationGetByteAddress() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("get-byte (%d)", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -96,9 +86,7 @@ func (vm *VirtualMachine) operationGetByteStack() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("get-byte {%d}", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -120,8 +108,6 @@ func (vm *VirtualMachine) operationPutByte() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("put-byte")
 	return nil
 }
 
@@ -142,9 +128,7 @@ func (vm *VirtualMachine) operationPutByteAddress() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("put-byte (%d)", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -165,9 +149,7 @@ func (vm *VirtualMachine) operationPutByteStack() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("put-byte {%d}", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -189,8 +171,6 @@ func (vm *VirtualMachine) operationAddByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("add-byte")
 	return nil
 }
 
@@ -212,8 +192,6 @@ func (vm *VirtualMachine) operationSubByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("sub-byte")
 	return nil
 }
 
@@ -235,12 +213,10 @@ func (vm *VirtualMachine) operationMulByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("mul-byte")
 	return nil
 }
 
-// operationDivByte takes 2 bytes from the stack, divides them and pushes the result
+// operationDivByte takes 2 bytes from the stack, divides them and pushes the result, faults on zero
 func (vm *VirtualMachine) operationDivByte() (err error) {
 	operant1, err := vm.stack.PopByte()
 	if err != nil {
@@ -252,14 +228,79 @@ func (vm *VirtualMachine) operationDivByte() (err error) {
 		return err
 	}
 
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+1)
+	}
+
 	err = vm.stack.PushByte(operant2 / operant1)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer++
+	return nil
+}
+
+// operationModByte takes 2 bytes from the stack, pushes the remainder of their division, faults on zero
+func (vm *VirtualMachine) operationModByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
 
-	vm.addLog("div-byte")
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+1)
+	}
+
+	result := operant2 % operant1
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIncByte adds one to the byte on top of the stack, FF wraps around to 00
+func (vm *VirtualMachine) operationIncByte() (err error) {
+	operant, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := operant + 1
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationDecByte subtracts one from the byte on top of the stack, 00 wraps around to FF
+func (vm *VirtualMachine) operationDecByte() (err error) {
+	operant, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := operant - 1
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
 	return nil
 }
 
@@ -286,8 +327,6 @@ func (vm *VirtualMachine) operationEqualByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("equal-byte")
 	return nil
 }
 
@@ -314,8 +353,6 @@ func (vm *VirtualMachine) operationUnequalByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("unequal-byte")
 	return nil
 }
 
@@ -342,8 +379,6 @@ func (vm *VirtualMachine) operationGreaterByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("greater-byte")
 	return nil
 }
 
@@ -370,8 +405,58 @@ func (vm *VirtualMachine) operationSmallerByte() (err error) {
 	}
 
 	vm.programPointer++
+	return nil
+}
+
+// operationGreaterEqualByte takes 2 bytes from the stack, pushes FF if the 2nd one is greater or equal, 00 if not
+func (vm *VirtualMachine) operationGreaterEqualByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 >= operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSmallerEqualByte takes 2 bytes from the stack, pushes FF if the 2nd one is smaller or equal, 00 if not
+func (vm *VirtualMachine) operationSmallerEqualByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 <= operant1 {
+		result = byte(0xFF)
+	}
 
-	vm.addLog("smaller-byte")
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
 	return nil
 }
 
@@ -393,8 +478,6 @@ func (vm *VirtualMachine) operationAndByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("and-byte")
 	return nil
 }
 
@@ -416,8 +499,6 @@ func (vm *VirtualMachine) operationOrByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("or-byte")
 	return nil
 }
 
@@ -434,8 +515,6 @@ func (vm *VirtualMachine) operationNotByte() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("not-byte")
 	return nil
 }
 
@@ -457,7 +536,91 @@ func (vm *VirtualMachine) operationXorByte() (err error) {
 	}
 
 	vm.programPointer++
+	return nil
+}
 
-	vm.addLog("xor-byte")
+// operationShlByte takes a count and a byte from the stack, pushes the byte shifted left, 00 from 8 on
+func (vm *VirtualMachine) operationShlByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := operant2 << operant1
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationShrByte takes a count and a byte from the stack, pushes the byte shifted right filling with zeroes, 00 from 8 on
+func (vm *VirtualMachine) operationShrByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := operant2 >> operant1
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSarByte takes a count and a byte from the stack, pushes the byte shifted right copying the sign bit, 00 or FF
+// from 8 on
+func (vm *VirtualMachine) operationSarByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	result := byte(int8(operant2) >> operant1)
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationInt8ToInt pops a byte and pushes it as an int, extending the sign
+func (vm *VirtualMachine) operationInt8ToInt() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(int(int8(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
 	return nil
 }
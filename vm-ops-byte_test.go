@@ -1,6 +1,7 @@
 package virtualmachine
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -652,3 +653,148 @@ func TestXorByte(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestDivByteFault(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(12)   // Operant: 12
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0)    // Operant: 0
+	p.WriteByte(0x4C) // Opcode: div-byte
+	p.WriteByte(0x00) // Opcode: end
+
+	err := p.Run(nil, nil)
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected: division by zero, got %v", err)
+	}
+}
+
+func TestModByte(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(200)  // Operant: 200
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(7)    // Operant: 7
+	p.WriteByte(0x50) // Opcode: mod-byte
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(200 % 7)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	p = NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(12)   // Operant: 12
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0)    // Operant: 0
+	p.WriteByte(0x50) // Opcode: mod-byte
+	p.WriteByte(0x00) // Opcode: end
+
+	err = p.Run(nil, nil)
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected: division by zero, got %v", err)
+	}
+}
+
+func TestIncDecByte(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  byte
+		result byte
+	}{
+		{0x54, 0x20, 0x21}, // inc-byte
+		{0x54, 0xFF, 0x00},
+		{0x56, 0x20, 0x1F}, // dec-byte
+		{0x56, 0x00, 0xFF},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.value)  // Operant: value
+		p.WriteByte(test.opcode) // Opcode: inc-byte or dec-byte
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %02X: %s", test.opcode, test.value, err.Error())
+		}
+	}
+}
+
+func TestGreaterSmallerEqualByte(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 byte
+		value2 byte
+		result byte
+	}{
+		{0x5A, 0x20, 0x10, 0xFF}, // greater-equal-byte
+		{0x5A, 0x20, 0x20, 0xFF},
+		{0x5A, 0x10, 0x20, 0x00},
+		{0x5D, 0x20, 0x10, 0x00}, // smaller-equal-byte
+		{0x5D, 0x20, 0x20, 0xFF},
+		{0x5D, 0x10, 0x20, 0xFF},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.value1) // Operant: value1
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.value2) // Operant: value2
+		p.WriteByte(test.opcode) // Opcode: greater-equal-byte or smaller-equal-byte
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %02X %02X: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestShiftByte(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  byte
+		count  byte
+		result byte
+	}{
+		{0x74, 0x81, 1, 0x02}, // shl-byte
+		{0x74, 0x81, 8, 0x00},
+		{0x76, 0x81, 1, 0x40}, // shr-byte
+		{0x76, 0x81, 8, 0x00},
+		{0x78, 0x81, 1, 0xC0}, // sar-byte
+		{0x78, 0x81, 8, 0xFF},
+		{0x78, 0x41, 1, 0x20},
+		{0x78, 0x41, 200, 0x00},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.value)  // Operant: value
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.count)  // Operant: count
+		p.WriteByte(test.opcode) // Opcode: shl-byte, shr-byte or sar-byte
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %02X %d: %s", test.opcode, test.value, test.count, err.Error())
+		}
+	}
+}
@@ -1,10 +1,5 @@
 package virtualmachine
 
-import (
-	"fmt"
-	"unsafe"
-)
-
 // operationRet takes an address from the stack and jumps there
 func (vm *VirtualMachine) operationRet() (err error) {
 	address, err := vm.stack.PopInt()
@@ -13,12 +8,26 @@ func (vm *VirtualMachine) operationRet() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	vm.programPointer = address
+	return nil
+}
+
+// operationReti pops an address from the stack, jumps there and enables interrupts again
+func (vm *VirtualMachine) operationReti() (err error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if address < 0 || address >= vm.memory.Size() {
+		return newVMError(ErrIllegalAddress, address)
+	}
 
-	vm.addLog("ret")
+	vm.interruptsEnabled = true
+	vm.programPointer = address
 	return nil
 }
 
@@ -30,12 +39,10 @@ func (vm *VirtualMachine) operationJmp() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	vm.programPointer = address
-
-	vm.addLog("jmp (%d)", address)
 	return nil
 }
 
@@ -47,7 +54,7 @@ func (vm *VirtualMachine) operationJmpzByte() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopByte()
@@ -60,8 +67,6 @@ func (vm *VirtualMachine) operationJmpzByte() (err error) {
 	} else {
 		vm.programPointer += 1
 	}
-
-	vm.addLog("jmpz-byte")
 	return nil
 }
 
@@ -73,7 +78,7 @@ func (vm *VirtualMachine) operationJmpzInt() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopInt()
@@ -86,8 +91,6 @@ func (vm *VirtualMachine) operationJmpzInt() (err error) {
 	} else {
 		vm.programPointer += 1
 	}
-
-	vm.addLog("jmpz-int")
 	return nil
 }
 
@@ -99,7 +102,7 @@ func (vm *VirtualMachine) operationJmpzFloat() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopFloat()
@@ -112,8 +115,6 @@ func (vm *VirtualMachine) operationJmpzFloat() (err error) {
 	} else {
 		vm.programPointer += 1
 	}
-
-	vm.addLog("jmpz-float")
 	return nil
 }
 
@@ -125,7 +126,7 @@ func (vm *VirtualMachine) operationJmpzByteAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopByte()
@@ -136,10 +137,8 @@ func (vm *VirtualMachine) operationJmpzByteAddress() (err error) {
 	if operant == byte(0) {
 		vm.programPointer = address
 	} else {
-		vm.programPointer += (1 + (int)(unsafe.Sizeof(address)))
+		vm.programPointer += (1 + IntSize)
 	}
-
-	vm.addLog("jmpz-byte (% X)", address)
 	return nil
 }
 
@@ -151,7 +150,7 @@ func (vm *VirtualMachine) operationJmpzIntAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopInt()
@@ -162,10 +161,8 @@ func (vm *VirtualMachine) operationJmpzIntAddress() (err error) {
 	if operant == int(0) {
 		vm.programPointer = address
 	} else {
-		vm.programPointer += (1 + (int)(unsafe.Sizeof(address)))
+		vm.programPointer += (1 + IntSize)
 	}
-
-	vm.addLog("jmpz-int (% X)", address)
 	return nil
 }
 
@@ -177,7 +174,7 @@ func (vm *VirtualMachine) operationJmpzFloatAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopFloat()
@@ -188,10 +185,8 @@ func (vm *VirtualMachine) operationJmpzFloatAddress() (err error) {
 	if operant == float64(0.0) {
 		vm.programPointer = address
 	} else {
-		vm.programPointer += (1 + (int)(unsafe.Sizeof(address)))
+		vm.programPointer += (1 + IntSize)
 	}
-
-	vm.addLog("jmpz-float (% X)", address)
 	return nil
 }
 
@@ -203,7 +198,7 @@ func (vm *VirtualMachine) operationJmpnzByte() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopByte()
@@ -216,8 +211,6 @@ func (vm *VirtualMachine) operationJmpnzByte() (err error) {
 	} else {
 		vm.programPointer += 1
 	}
-
-	vm.addLog("jmpnz-byte")
 	return nil
 }
 
@@ -229,7 +222,7 @@ func (vm *VirtualMachine) operationJmpnzInt() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopInt()
@@ -242,8 +235,6 @@ func (vm *VirtualMachine) operationJmpnzInt() (err error) {
 	} else {
 		vm.programPointer += 1
 	}
-
-	vm.addLog("jmpz-int")
 	return nil
 }
 
@@ -255,7 +246,7 @@ func (vm *VirtualMachine) operationJmpnzFloat() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopFloat()
@@ -268,8 +259,6 @@ func (vm *VirtualMachine) operationJmpnzFloat() (err error) {
 	} else {
 		vm.programPointer += 1
 	}
-
-	vm.addLog("jmpnz-float")
 	return nil
 }
 
@@ -281,7 +270,7 @@ func (vm *VirtualMachine) operationJmpnzByteAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopByte()
@@ -292,10 +281,8 @@ func (vm *VirtualMachine) operationJmpnzByteAddress() (err error) {
 	if operant != byte(0) {
 		vm.programPointer = address
 	} else {
-		vm.programPointer += (1 + (int)(unsafe.Sizeof(address)))
+		vm.programPointer += (1 + IntSize)
 	}
-
-	vm.addLog("jmpnz-byte()")
 	return nil
 }
 
@@ -307,7 +294,7 @@ func (vm *VirtualMachine) operationJmpnzIntAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopInt()
@@ -318,10 +305,8 @@ func (vm *VirtualMachine) operationJmpnzIntAddress() (err error) {
 	if operant != int(0) {
 		vm.programPointer = address
 	} else {
-		vm.programPointer += (1 + (int)(unsafe.Sizeof(address)))
+		vm.programPointer += (1 + IntSize)
 	}
-
-	vm.addLog("jmpz-int()")
 	return nil
 }
 
@@ -333,7 +318,7 @@ func (vm *VirtualMachine) operationJmpnzFloatAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	operant, err := vm.stack.PopFloat()
@@ -344,10 +329,8 @@ func (vm *VirtualMachine) operationJmpnzFloatAddress() (err error) {
 	if operant != float64(0.0) {
 		vm.programPointer = address
 	} else {
-		vm.programPointer += (1 + (int)(unsafe.Sizeof(address)))
+		vm.programPointer += (1 + IntSize)
 	}
-
-	vm.addLog("jmpnz-float()")
 	return nil
 }
 
@@ -359,7 +342,7 @@ func (vm *VirtualMachine) operationCall() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
 	err = vm.stack.PushInt(vm.programPointer + 1)
@@ -368,8 +351,6 @@ func (vm *VirtualMachine) operationCall() (err error) {
 	}
 
 	vm.programPointer = address
-
-	vm.addLog("call")
 	return nil
 }
 
@@ -381,16 +362,45 @@ func (vm *VirtualMachine) operationCallAddress() (err error) {
 	}
 
 	if address < 0 || address >= vm.memory.Size() {
-		return fmt.Errorf("illegal address")
+		return newVMError(ErrIllegalAddress, address)
 	}
 
-	err = vm.stack.PushInt(vm.programPointer + (int)(unsafe.Sizeof(address)) + 1)
+	err = vm.stack.PushInt(vm.programPointer + IntSize + 1)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer = address
+	return nil
+}
+
+// operationSyscall takes the number of a host function as operant and calls it, the host function takes its arguments from
+// the stack and leaves its results there
+func (vm *VirtualMachine) operationSyscall() (err error) {
+	id, err := vm.memory.GetInt(vm.programPointer + 1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.callHost(id)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += IntSize + 1
+	return nil
+}
+
+// operationEi enables interrupts
+func (vm *VirtualMachine) operationEi() (err error) {
+	vm.interruptsEnabled = true
+	vm.programPointer++
+	return nil
+}
 
-	vm.addLog("call (%d)", address)
+// operationDi disables interrupts
+func (vm *VirtualMachine) operationDi() (err error) {
+	vm.interruptsEnabled = false
+	vm.programPointer++
 	return nil
 }
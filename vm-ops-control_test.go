@@ -1,6 +1,9 @@
 package virtualmachine
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestRet(t *testing.T) {
 	testAddress1 := int(11)              // Fine address
@@ -34,7 +37,7 @@ func TestRet(t *testing.T) {
 
 	s = NewBuffer()
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -46,7 +49,7 @@ func TestRet(t *testing.T) {
 
 	s = NewBuffer()
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -82,7 +85,7 @@ func TestJmp(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -94,7 +97,7 @@ func TestJmp(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -165,7 +168,7 @@ func TestJmpzByte(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -185,7 +188,7 @@ func TestJmpzByte(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -256,7 +259,7 @@ func TestJmpzInt(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -276,7 +279,7 @@ func TestJmpzInt(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -347,7 +350,7 @@ func TestJmpzFloat(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -367,7 +370,7 @@ func TestJmpzFloat(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -435,7 +438,7 @@ func TestJmpzByteAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -454,7 +457,7 @@ func TestJmpzByteAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -522,7 +525,7 @@ func TestJmpzIntAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -541,7 +544,7 @@ func TestJmpzIntAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -609,7 +612,7 @@ func TestJmpzFloatAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -628,7 +631,7 @@ func TestJmpzFloatAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -699,7 +702,7 @@ func TestJmpnzByte(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -719,7 +722,7 @@ func TestJmpnzByte(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -790,7 +793,7 @@ func TestJmpnzInt(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -810,7 +813,7 @@ func TestJmpnzInt(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -881,7 +884,7 @@ func TestJmpnzFloat(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -901,7 +904,7 @@ func TestJmpnzFloat(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -969,7 +972,7 @@ func TestJmpnzByteAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -988,7 +991,7 @@ func TestJmpnzByteAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -1056,7 +1059,7 @@ func TestJmpnzIntAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -1075,7 +1078,7 @@ func TestJmpnzIntAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -1143,7 +1146,7 @@ func TestJmpnzFloatAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -1162,7 +1165,7 @@ func TestJmpnzFloatAddress(t *testing.T) {
 	s = NewBuffer()
 
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -1201,7 +1204,7 @@ func TestCall(t *testing.T) {
 
 	s = NewBuffer()
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -1213,7 +1216,7 @@ func TestCall(t *testing.T) {
 
 	s = NewBuffer()
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
@@ -1250,7 +1253,7 @@ func TestCallAddress(t *testing.T) {
 
 	s = NewBuffer()
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 
@@ -1261,7 +1264,7 @@ func TestCallAddress(t *testing.T) {
 
 	s = NewBuffer()
 	err = p.Run(s, nil)
-	if err.Error() != "illegal address" {
+	if !errors.Is(err, ErrIllegalAddress) {
 		t.Errorf("Expected: illegal address")
 	}
 }
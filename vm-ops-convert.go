@@ -0,0 +1,178 @@
+package virtualmachine
+
+import "math"
+
+// operationByteToInt pops a byte and pushes it as an int
+func (vm *VirtualMachine) operationByteToInt() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(int(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIntToByte pops an int and pushes its lower 8 bits as a byte
+func (vm *VirtualMachine) operationIntToByte() (err error) {
+	value, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(byte(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIntToByteSaturate pops an int and pushes it as a byte, clamped to 0..255
+func (vm *VirtualMachine) operationIntToByteSaturate() (err error) {
+	value, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if value < 0 {
+		value = 0
+	}
+	if value > math.MaxUint8 {
+		value = math.MaxUint8
+	}
+
+	err = vm.stack.PushByte(byte(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationByteToFloat pops a byte and pushes it as a float
+func (vm *VirtualMachine) operationByteToFloat() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(float64(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIntToFloat pops an int and pushes it as the nearest float
+func (vm *VirtualMachine) operationIntToFloat() (err error) {
+	value, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(float64(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFloatToInt pops a float and pushes it as an int, truncated towards zero
+func (vm *VirtualMachine) operationFloatToInt() (err error) {
+	value, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(saturateInt(math.Trunc(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFloatToIntFloor pops a float and pushes it as an int, rounded down
+func (vm *VirtualMachine) operationFloatToIntFloor() (err error) {
+	value, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(saturateInt(math.Floor(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFloatToIntRound pops a float and pushes it as an int, rounded to the nearest, halves away from zero
+func (vm *VirtualMachine) operationFloatToIntRound() (err error) {
+	value, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(saturateInt(math.Round(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFloatToIntTrap pops a float and pushes it as an int, truncated towards zero, faulting when it doesn't fit
+func (vm *VirtualMachine) operationFloatToIntTrap() (err error) {
+	value, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	if math.IsNaN(value) {
+		return vm.fault(ErrInvalidConversion, vm.programPointer+1)
+	}
+	value = math.Trunc(value)
+	if value < math.MinInt || value >= -math.MinInt {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// saturateInt converts a whole float to an int, clamping it to the range of an int of the host, NaN becomes 0
+func saturateInt(value float64) int {
+	switch {
+	case math.IsNaN(value):
+		return 0
+	case value >= -math.MinInt:
+		return math.MaxInt
+	case value <= math.MinInt:
+		return math.MinInt
+	}
+
+	return int(value)
+}
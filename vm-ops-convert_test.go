@@ -0,0 +1,139 @@
+package virtualmachine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestByteToInt(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0xFE) // Operant: 254
+	p.WriteByte(0x80) // Opcode: byte-to-int
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(254)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestIntToByte(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  int
+		result byte
+	}{
+		{0x81, 0x1234, 0x34}, // int-to-byte
+		{0x81, -1, 0xFF},
+		{0x82, 0x1234, 0xFF}, // int-to-byte-sat
+		{0x82, -1, 0x00},
+		{0x82, 200, 200},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value)   // Operant: value
+		p.WriteByte(test.opcode) // Opcode: int-to-byte or int-to-byte-sat
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %d: %s", test.opcode, test.value, err.Error())
+		}
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0xFE) // Operant: 254
+	p.WriteByte(0x83) // Opcode: byte-to-float
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(-325)  // Operant: -325
+	p.WriteByte(0x84) // Opcode: int-to-float
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteFloat(254)
+	s.WriteFloat(-325)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestFloatToInt(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  float64
+		result int
+	}{
+		{0x85, 2.7, 2}, // float-to-int
+		{0x85, -2.7, -2},
+		{0x85, math.NaN(), 0},
+		{0x85, math.Inf(1), math.MaxInt},
+		{0x85, -1e300, math.MinInt},
+		{0x86, 2.7, 2}, // float-to-int-floor
+		{0x86, -2.2, -3},
+		{0x87, 2.5, 3}, // float-to-int-round
+		{0x87, -2.5, -3},
+		{0x87, 2.4, 2},
+		{0x88, -2.7, -2}, // float-to-int-trap
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x0A)        // Opcode: push-float
+		p.WriteFloat(test.value) // Operant: value
+		p.WriteByte(test.opcode) // Opcode: float-to-int...
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %g: %s", test.opcode, test.value, err.Error())
+		}
+	}
+}
+
+func TestFloatToIntTrap(t *testing.T) {
+	tests := []struct {
+		value float64
+		kind  error
+	}{
+		{math.NaN(), ErrInvalidConversion},
+		{math.Inf(-1), ErrIntegerOverflow},
+		{9.3e18, ErrIntegerOverflow},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x0A)        // Opcode: push-float
+		p.WriteFloat(test.value) // Operant: value
+		p.WriteByte(0x88)        // Opcode: float-to-int-trap
+		p.WriteByte(0x00)        // Opcode: end
+
+		err := p.Run(nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%g: expected %v, got %v", test.value, test.kind, err)
+			continue
+		}
+
+		var vmErr *VMError
+		if !errors.As(err, &vmErr) || vmErr.ProgramPointer != 1+FloatSize || vmErr.Opcode != 0x88 {
+			t.Errorf("Expected fault at float-to-int-trap, got %v", err)
+		}
+	}
+}
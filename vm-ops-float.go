@@ -1,6 +1,6 @@
 package virtualmachine
 
-import "unsafe"
+import "math"
 
 // operationPushFloat takes the following 8 bytes and pushes them on the stack as a float
 func (vm *VirtualMachine) operationPushFloat() (err error) {
@@ -14,9 +14,7 @@ func (vm *VirtualMachine) operationPushFloat() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("push-float %f", operant)
+	vm.programPointer += 1 + FloatSize
 	return nil
 }
 
@@ -28,8 +26,6 @@ func (vm *VirtualMachine) operationPopFloat() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("pop-float")
 	return nil
 }
 
@@ -51,8 +47,6 @@ func (vm *VirtualMachine) operationGetFloat() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("get-float")
 	return nil
 }
 
@@ -74,8 +68,6 @@ func (vm *VirtualMachine) operationPutFloat() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("put-float")
 	return nil
 }
 
@@ -96,9 +88,7 @@ func (vm *VirtualMachine) operationGetFloatAddress() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("get-float (%d)", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -119,9 +109,7 @@ func (vm *VirtualMachine) operationPutFloatAddress() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("put-float (%d)", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -142,9 +130,7 @@ func (vm *VirtualMachine) operationGetFloatStack() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("get-float {%d}", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -165,9 +151,7 @@ func (vm *VirtualMachine) operationPutFloatStack() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("put-float {%d}", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -189,8 +173,6 @@ func (vm *VirtualMachine) operationAddFloat() error {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("add-float")
 	return nil
 }
 
@@ -212,8 +194,6 @@ func (vm *VirtualMachine) operationSubFloat() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("sub-float")
 	return nil
 }
 
@@ -235,12 +215,10 @@ func (vm *VirtualMachine) operationMulFloat() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("mul-float")
 	return nil
 }
 
-// operationDivFloat takes 2 floats from the stack, divides them and pushes the result
+// operationDivFloat takes 2 floats from the stack, divides them and pushes the result, by zero depends on the policy
 func (vm *VirtualMachine) operationDivFloat() (err error) {
 	operant1, err := vm.stack.PopFloat()
 	if err != nil {
@@ -252,14 +230,52 @@ func (vm *VirtualMachine) operationDivFloat() (err error) {
 		return err
 	}
 
+	if operant1 == 0 && vm.floatDivision == FloatDivisionTrap {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+1)
+	}
+
 	err = vm.stack.PushFloat(operant2 / operant1)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer++
+	return nil
+}
 
-	vm.addLog("div-float")
+// operationNegFloat takes a float from the stack and pushes it negated
+func (vm *VirtualMachine) operationNegFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := -operant
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationAbsFloat takes a float from the stack and pushes its absolute value
+func (vm *VirtualMachine) operationAbsFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Abs(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
 	return nil
 }
 
@@ -286,8 +302,6 @@ func (vm *VirtualMachine) operationEqualFloat() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("equal-float")
 	return nil
 }
 
@@ -314,8 +328,6 @@ func (vm *VirtualMachine) operationUnequalFloat() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("unequal-float")
 	return nil
 }
 
@@ -342,8 +354,6 @@ func (vm *VirtualMachine) operationGreaterFloat() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("greater-float")
 	return nil
 }
 
@@ -370,7 +380,57 @@ func (vm *VirtualMachine) operationSmallerFloat() (err error) {
 	}
 
 	vm.programPointer++
+	return nil
+}
+
+// operationGreaterEqualFloat takes 2 floats from the stack, pushes FF if the bottom one is greater or equal, 00 otherwise
+func (vm *VirtualMachine) operationGreaterEqualFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 >= operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSmallerEqualFloat takes 2 floats from the stack, pushes FF if the bottom one is smaller or equal, 00 otherwise
+func (vm *VirtualMachine) operationSmallerEqualFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
 
-	vm.addLog("smaller-float")
+	result := byte(0x00)
+	if operant2 <= operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
 	return nil
 }
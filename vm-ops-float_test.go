@@ -1,6 +1,8 @@
 package virtualmachine
 
 import (
+	"errors"
+	"math"
 	"testing"
 )
 
@@ -440,3 +442,94 @@ func TestSmallerFloat(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestDivFloatByZero(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x0A)  // Opcode: push-float
+	p.WriteFloat(12.5) // Operant: 12.5
+	p.WriteByte(0x0A)  // Opcode: push-float
+	p.WriteFloat(0.0)  // Operant: 0.0
+	p.WriteByte(0x4E)  // Opcode: div-float
+	p.WriteByte(0x00)  // Opcode: end
+
+	// IEEE by default
+	s := NewBuffer()
+	s.WriteFloat(math.Inf(1))
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Trapped on request
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	vm.SetFloatDivision(FloatDivisionTrap)
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected: division by zero, got %v", err)
+	}
+}
+
+func TestNegAbsFloat(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x0A)  // Opcode: push-float
+	p.WriteFloat(-2.5) // Operant: -2.5
+	p.WriteByte(0x53)  // Opcode: neg-float
+	p.WriteByte(0x0A)  // Opcode: push-float
+	p.WriteFloat(-2.5) // Operant: -2.5
+	p.WriteByte(0x59)  // Opcode: abs-float
+	p.WriteByte(0x0A)  // Opcode: push-float
+	p.WriteFloat(2.5)  // Operant: 2.5
+	p.WriteByte(0x59)  // Opcode: abs-float
+	p.WriteByte(0x00)  // Opcode: end
+
+	s := NewBuffer()
+	s.WriteFloat(2.5)
+	s.WriteFloat(2.5)
+	s.WriteFloat(2.5)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGreaterSmallerEqualFloat(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 float64
+		value2 float64
+		result byte
+	}{
+		{0x5C, 2.5, 1.5, 0xFF}, // greater-equal-float
+		{0x5C, 2.5, 2.5, 0xFF},
+		{0x5C, 1.5, 2.5, 0x00},
+		{0x5C, math.NaN(), 2.5, 0x00},
+		{0x5F, 2.5, 1.5, 0x00}, // smaller-equal-float
+		{0x5F, 2.5, 2.5, 0xFF},
+		{0x5F, 1.5, 2.5, 0xFF},
+		{0x5F, 1.5, math.NaN(), 0x00},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x0A)         // Opcode: push-float
+		p.WriteFloat(test.value1) // Operant: value1
+		p.WriteByte(0x0A)         // Opcode: push-float
+		p.WriteFloat(test.value2) // Operant: value2
+		p.WriteByte(test.opcode)  // Opcode: greater-equal-float or smaller-equal-float
+		p.WriteByte(0x00)         // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %g %g: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package virtualmachine
+
+// operationAllocN takes a number of bytes as operant, allocates them on the heap and pushes the address of the first one
+func (vm *VirtualMachine) operationAllocN() (err error) {
+	size, err := vm.memory.GetInt(vm.programPointer + 1)
+	if err != nil {
+		return err
+	}
+
+	address, err := vm.heap.Alloc(size)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(address)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += IntSize + 1
+	return nil
+}
+
+// operationAlloc pops a number of bytes, allocates them on the heap and pushes the address of the first one
+func (vm *VirtualMachine) operationAlloc() (err error) {
+	size, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	address, err := vm.heap.Alloc(size)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(address)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFree pops the address of a block on the heap and frees it
+func (vm *VirtualMachine) operationFree() (err error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.heap.Free(address)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRealloc pops a number of bytes and the address of a block on the heap, resizes the block and pushes its new
+// address
+func (vm *VirtualMachine) operationRealloc() (err error) {
+	size, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	address, err = vm.heap.Realloc(address, size)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(address)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationGC runs the garbage collector
+func (vm *VirtualMachine) operationGC() (err error) {
+	err = vm.heap.collect(-1)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
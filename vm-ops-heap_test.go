@@ -0,0 +1,127 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+)
+
+func heapMachine(t *testing.T, debug bool) *VirtualMachine {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.HeapDebug = debug
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return vm
+}
+
+func TestAllocFree(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(16)    // Operant: 16
+	p.WriteByte(0xC1) // Opcode: alloc
+	p.WriteByte(0xC2) // Opcode: free
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0xC1) // Opcode: alloc
+	p.WriteByte(0x00) // Opcode: end
+
+	// The second block is freed and handed out again
+	first := p.Size() + heapHeaderSize
+	second := first + 8 + heapHeaderSize
+
+	s := NewBuffer()
+	s.WriteInt(first)
+	s.WriteInt(second)
+
+	vm := heapMachine(t, false)
+	err := p.RunOn(vm, s, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stats, err := vm.Heap().Stats()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if stats.Allocs != 3 || stats.Frees != 1 || stats.InUse != 16 || stats.Blocks != 2 || stats.Peak != 24 {
+		t.Errorf("Unexpected heap statistics %+v", stats)
+	}
+}
+
+func TestRealloc(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xC0) // Opcode: alloc-n
+	p.WriteInt(8)     // Operant: 8
+	p.WriteByte(0x91) // Opcode: dup-int
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(42)    // Operant: 42
+	p.WriteByte(0x95) // Opcode: swap-int
+	p.WriteByte(0x19) // Opcode: put-int
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(24)    // Operant: 24
+	p.WriteByte(0xC3) // Opcode: realloc
+	p.WriteByte(0x11) // Opcode: get-int
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(42)
+
+	err := p.RunOn(heapMachine(t, true), s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestHeapFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		debug bool
+		kind  error
+		build func(p *Program)
+	}{
+		{"too large", false, ErrHeapExhausted, func(p *Program) {
+			p.WriteByte(0xC0)       // Opcode: alloc-n
+			p.WriteInt(MEMORY_SIZE) // Operant: MEMORY_SIZE
+		}},
+		{"negative", false, ErrHeapExhausted, func(p *Program) {
+			p.WriteByte(0x09) // Opcode: push-int
+			p.WriteInt(-1)    // Operant: -1
+			p.WriteByte(0xC1) // Opcode: alloc
+		}},
+		{"not a block", false, ErrIllegalAddress, func(p *Program) {
+			p.WriteByte(0x09) // Opcode: push-int
+			p.WriteInt(0)     // Operant: 0
+			p.WriteByte(0xC2) // Opcode: free
+		}},
+		{"double free", true, ErrDoubleFree, func(p *Program) {
+			p.WriteByte(0xC0) // Opcode: alloc-n
+			p.WriteInt(8)     // Operant: 8
+			p.WriteByte(0x91) // Opcode: dup-int
+			p.WriteByte(0xC2) // Opcode: free
+			p.WriteByte(0xC2) // Opcode: free
+		}},
+		{"use after free", true, ErrUseAfterFree, func(p *Program) {
+			p.WriteByte(0xC0) // Opcode: alloc-n
+			p.WriteInt(8)     // Operant: 8
+			p.WriteByte(0x91) // Opcode: dup-int
+			p.WriteByte(0xC2) // Opcode: free
+			p.WriteByte(0x11) // Opcode: get-int
+		}},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		test.build(p)
+		p.WriteByte(0x00) // Opcode: end
+
+		err := p.RunOn(heapMachine(t, test.debug), nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.kind, err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package virtualmachine
 
-import "unsafe"
+import (
+	"math"
+	"math/bits"
+)
 
 // operationPushInt takes the following 8 bytes from memory and pushes them as an int
 func (vm *VirtualMachine) operationPushInt() (err error) {
@@ -14,9 +17,7 @@ func (vm *VirtualMachine) operationPushInt() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("push-int %d", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -28,8 +29,6 @@ func (vm *VirtualMachine) operationPopInt() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("pop-int")
 	return nil
 }
 
@@ -51,8 +50,6 @@ func (vm *VirtualMachine) operationGetInt() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("get-int")
 	return nil
 }
 
@@ -74,8 +71,6 @@ func (vm *VirtualMachine) operationPutInt() (err error) {
 	}
 
 	vm.programPointer += 1
-
-	vm.addLog("put-int")
 	return nil
 }
 
@@ -96,9 +91,7 @@ func (vm *VirtualMachine) operationGetIntAddress() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("get-int (%d)", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -119,9 +112,7 @@ func (vm *VirtualMachine) operationPutIntAddress() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("put-int (%d)", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -142,9 +133,7 @@ func (vm *VirtualMachine) operationGetIntStack() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("get-int {%d}", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -165,9 +154,7 @@ func (vm *VirtualMachine) operationPutIntStack() (err error) {
 		return err
 	}
 
-	vm.programPointer += 1 + (int)(unsafe.Sizeof(operant))
-
-	vm.addLog("put-int {%d}", operant)
+	vm.programPointer += 1 + IntSize
 	return nil
 }
 
@@ -183,14 +170,17 @@ func (vm *VirtualMachine) operationAddInt() (err error) {
 		return err
 	}
 
-	err = vm.stack.PushInt(operant1 + operant2)
+	result := operant1 + operant2
+	if vm.integerOverflow == IntegerOverflowTrap && addOverflows(operant1, operant2, result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(result)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer++
-
-	vm.addLog("add-int")
 	return nil
 }
 
@@ -206,14 +196,17 @@ func (vm *VirtualMachine) operationSubInt() (err error) {
 		return err
 	}
 
-	err = vm.stack.PushInt(operant2 - operant1)
+	result := operant2 - operant1
+	if vm.integerOverflow == IntegerOverflowTrap && subOverflows(operant2, operant1, result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(result)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer++
-
-	vm.addLog("sub-int")
 	return nil
 }
 
@@ -229,14 +222,17 @@ func (vm *VirtualMachine) operationMulInt() (err error) {
 		return err
 	}
 
-	err = vm.stack.PushInt(operant2 * operant1)
+	result := operant2 * operant1
+	if vm.integerOverflow == IntegerOverflowTrap && mulOverflows(operant2, operant1, result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(result)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer++
-
-	vm.addLog("mul-int")
 	return nil
 }
 
@@ -252,14 +248,139 @@ func (vm *VirtualMachine) operationDivInt() (err error) {
 		return err
 	}
 
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+1)
+	}
+	if operant2 == math.MinInt && operant1 == -1 {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
 	err = vm.stack.PushInt(operant2 / operant1)
 	if err != nil {
 		return err
 	}
 
 	vm.programPointer++
+	return nil
+}
+
+// operationModInt takes 2 ints from the stack, pushes the remainder of their division with the sign of the 2nd one, faults on
+// zero
+func (vm *VirtualMachine) operationModInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
 
-	vm.addLog("div-int")
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+1)
+	}
+
+	result := operant2 % operant1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationNegInt takes an int from the stack and pushes it negated, overflow of the smallest int depends on the policy
+func (vm *VirtualMachine) operationNegInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if operant == math.MinInt && vm.integerOverflow == IntegerOverflowTrap {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	result := -operant
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationAbsInt takes an int from the stack and pushes its absolute value, overflow of the smallest int depends on the
+// policy
+func (vm *VirtualMachine) operationAbsInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if operant == math.MinInt && vm.integerOverflow == IntegerOverflowTrap {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	result := operant
+	if operant < 0 {
+		result = -operant
+	}
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIncInt adds one to the int on top of the stack, overflow depends on the policy
+func (vm *VirtualMachine) operationIncInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if operant == math.MaxInt && vm.integerOverflow == IntegerOverflowTrap {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	result := operant + 1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationDecInt subtracts one from the int on top of the stack, overflow depends on the policy
+func (vm *VirtualMachine) operationDecInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	if operant == math.MinInt && vm.integerOverflow == IntegerOverflowTrap {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	result := operant - 1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
 	return nil
 }
 
@@ -286,8 +407,6 @@ func (vm *VirtualMachine) operationEqualInt() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("equal-int")
 	return nil
 }
 
@@ -314,8 +433,6 @@ func (vm *VirtualMachine) operationUnequalInt() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("unequal-int")
 	return nil
 }
 
@@ -342,8 +459,6 @@ func (vm *VirtualMachine) operationGreaterInt() (err error) {
 	}
 
 	vm.programPointer++
-
-	vm.addLog("greater-int")
 	return nil
 }
 
@@ -370,7 +485,435 @@ func (vm *VirtualMachine) operationSmallerInt() (err error) {
 	}
 
 	vm.programPointer++
+	return nil
+}
+
+// operationGreaterEqualInt takes 2 ints from the stack, pushes FF if the second one is greater or equal, 00 if not
+func (vm *VirtualMachine) operationGreaterEqualInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 >= operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSmallerEqualInt takes 2 ints from the stack, pushes FF if the second one is smaller or equal, 00 if not
+func (vm *VirtualMachine) operationSmallerEqualInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 <= operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationShlInt takes a byte count and an int from the stack, pushes the int shifted left, 0 from 64 on
+func (vm *VirtualMachine) operationShlInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := int64(operant2) << operant1
+	if !fitsInt(result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationShrInt takes a byte count and an int from the stack, pushes the int shifted right filling with zeroes, 0 from
+// 64 on
+func (vm *VirtualMachine) operationShrInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := int64(uint64(operant2) >> operant1)
+	if !fitsInt(result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSarInt takes a byte count and an int from the stack, pushes the int shifted right copying the sign bit, 0 or -1
+// from 64 on
+func (vm *VirtualMachine) operationSarInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := operant2 >> operant1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationAndInt takes 2 ints from the stack, pushes a bit-wise AND
+func (vm *VirtualMachine) operationAndInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := operant2 & operant1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationOrInt takes 2 ints from the stack, pushes a bit-wise OR
+func (vm *VirtualMachine) operationOrInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := operant2 | operant1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationNotInt takes an int from the stack, pushes a bit-wise NOT
+func (vm *VirtualMachine) operationNotInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := ^operant
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationXorInt takes 2 ints from the stack, pushes a bit-wise XOR
+func (vm *VirtualMachine) operationXorInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := operant2 ^ operant1
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRotlInt takes a byte count and an int from the stack, pushes the int rotated left by the count modulo 64
+func (vm *VirtualMachine) operationRotlInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := int64(bits.RotateLeft64(uint64(operant2), int(operant1%64)))
+	if !fitsInt(result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRotrInt takes a byte count and an int from the stack, pushes the int rotated right by the count modulo 64
+func (vm *VirtualMachine) operationRotrInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := int64(bits.RotateLeft64(uint64(operant2), -int(operant1%64)))
+	if !fitsInt(result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationPopcountInt takes an int from the stack, pushes the number of bits set as a byte
+func (vm *VirtualMachine) operationPopcountInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := byte(bits.OnesCount64(uint64(operant)))
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
 
-	vm.addLog("smaller-int")
+	vm.programPointer += 2
 	return nil
 }
+
+// operationClzInt takes an int from the stack, pushes the number of leading zero bits as a byte, 64 for 0
+func (vm *VirtualMachine) operationClzInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := byte(bits.LeadingZeros64(uint64(operant)))
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationCtzInt takes an int from the stack, pushes the number of trailing zero bits as a byte, 64 for 0
+func (vm *VirtualMachine) operationCtzInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := byte(bits.TrailingZeros64(uint64(operant)))
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationBitTestInt takes a bit number and an int from the stack, pushes FF if the bit is set, 00 if not or beyond
+// the int
+func (vm *VirtualMachine) operationBitTestInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant1 < 64 && int64(operant2)&(1<<operant1) != 0 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationBitSetInt takes a bit number and an int from the stack, pushes the int with the bit set, numbers beyond the
+// int leave it as it is
+func (vm *VirtualMachine) operationBitSetInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := int64(operant2)
+	if operant1 < 64 {
+		result |= 1 << operant1
+	}
+	if !fitsInt(result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt(int(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationBitClearInt takes a bit number and an int from the stack, pushes the int with the bit cleared, numbers beyond
+// the int leave it as it is
+func (vm *VirtualMachine) operationBitClearInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	result := int64(operant2)
+	if operant1 < 64 {
+		result &^= 1 << operant1
+	}
+	if !fitsInt(result) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt(int(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// addOverflows tells whether a + b wrapped around to result
+func addOverflows(a int, b int, result int) bool {
+	return (a >= 0) == (b >= 0) && (result >= 0) != (a >= 0)
+}
+
+// subOverflows tells whether a - b wrapped around to result
+func subOverflows(a int, b int, result int) bool {
+	return (a >= 0) != (b >= 0) && (result >= 0) != (a >= 0)
+}
+
+// mulOverflows tells whether a * b wrapped around to result
+func mulOverflows(a int, b int, result int) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+
+	return result/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt)
+}
@@ -0,0 +1,539 @@
+package virtualmachine
+
+import "math"
+
+// operationPushInt16 takes the following 2 bytes from memory and pushes them as an int16
+func (vm *VirtualMachine) operationPushInt16() (err error) {
+	operant, err := vm.memory.GetInt16(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(operant)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + Int16Size
+	return nil
+}
+
+// operationPopInt16 pops an int16 from stack (and looses it)
+func (vm *VirtualMachine) operationPopInt16() (err error) {
+	_, err = vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGetInt16 pops an address from stack and pushes the int16 from that memory-address
+func (vm *VirtualMachine) operationGetInt16() (err error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.memory.GetInt16(address)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationPutInt16 pops an address and pops an int16 into that memory-address
+func (vm *VirtualMachine) operationPutInt16() (err error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.memory.PutInt16(address, value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGetInt16Address takes an address and pushes the int16 from that memory-address
+func (vm *VirtualMachine) operationGetInt16Address() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.memory.GetInt16(operant)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationPutInt16Address takes an address and pops an int16 into that memory-address
+func (vm *VirtualMachine) operationPutInt16Address() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.memory.PutInt16(operant, value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationGetInt16Stack takes an offset and pushes the int16 from the memory-address stack-pointer + opperant
+func (vm *VirtualMachine) operationGetInt16Stack() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.GetInt16(operant)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationPutInt16Stack takes an offset and pops an int16 to the memory-address (stack-pointer + opperant)
+func (vm *VirtualMachine) operationPutInt16Stack() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PutInt16(operant, value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationAddInt16 takes 2 int16s from the stack, adds them and pushes the result
+func (vm *VirtualMachine) operationAddInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := int(operant2) + int(operant1)
+	if vm.integerOverflow == IntegerOverflowTrap && (result < math.MinInt16 || result > math.MaxInt16) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt16(int16(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationSubInt16 takes 2 int16s from the stack, subtracts them and pushes the result
+func (vm *VirtualMachine) operationSubInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := int(operant2) - int(operant1)
+	if vm.integerOverflow == IntegerOverflowTrap && (result < math.MinInt16 || result > math.MaxInt16) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt16(int16(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationMulInt16 takes 2 int16s from the stack, multiplies them and pushes the result
+func (vm *VirtualMachine) operationMulInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := int(operant2) * int(operant1)
+	if vm.integerOverflow == IntegerOverflowTrap && (result < math.MinInt16 || result > math.MaxInt16) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt16(int16(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationDivInt16 takes 2 int16s from the stack, divides them and pushes the result
+func (vm *VirtualMachine) operationDivInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+2)
+	}
+	if operant2 == math.MinInt16 && operant1 == -1 {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt16(operant2 / operant1)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationDivUint16 takes 2 int16s from the stack, divides them as unsigned values and pushes the result
+func (vm *VirtualMachine) operationDivUint16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt16(int16(uint16(operant2) / uint16(operant1)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationEqualInt16 takes 2 int16s from the stack, pushes FF if equal, 00 if not
+func (vm *VirtualMachine) operationEqualInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant1 == operant2 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationUnequalInt16 takes 2 int16s from the stack, pushes FF if not equal, 00 if not
+func (vm *VirtualMachine) operationUnequalInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant1 != operant2 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGreaterInt16 takes 2 int16s from the stack, pushes FF if the second one is greater, 00 if not
+func (vm *VirtualMachine) operationGreaterInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 > operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGreaterUint16 takes 2 int16s from the stack, pushes FF if the second one is greater as unsigned values, 00 if not
+func (vm *VirtualMachine) operationGreaterUint16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if uint16(operant2) > uint16(operant1) {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationSmallerInt16 takes 2 int16s from the stack, pushes FF if the second one is smaller, 00 if not
+func (vm *VirtualMachine) operationSmallerInt16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 < operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationSmallerUint16 takes 2 int16s from the stack, pushes FF if the second one is smaller as unsigned values, 00 if not
+func (vm *VirtualMachine) operationSmallerUint16() (err error) {
+	operant1, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if uint16(operant2) < uint16(operant1) {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt16ToInt pops an int16 and pushes it as an int, extending the sign
+func (vm *VirtualMachine) operationInt16ToInt() (err error) {
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(int(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationUint16ToInt pops an int16 and pushes it as an int, extending with zeroes
+func (vm *VirtualMachine) operationUint16ToInt() (err error) {
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(int(uint16(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationIntToInt16 pops an int and pushes its lower 16 bits as an int16
+func (vm *VirtualMachine) operationIntToInt16() (err error) {
+	value, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(int16(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt8ToInt16 pops a byte and pushes it as an int16, extending the sign
+func (vm *VirtualMachine) operationInt8ToInt16() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(int16(int8(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationByteToInt16 pops a byte and pushes it as an int16, extending with zeroes
+func (vm *VirtualMachine) operationByteToInt16() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(int16(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt16ToByte pops an int16 and pushes its lower 8 bits as a byte
+func (vm *VirtualMachine) operationInt16ToByte() (err error) {
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(byte(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
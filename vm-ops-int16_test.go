@@ -0,0 +1,374 @@
+package virtualmachine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPushInt16(t *testing.T) {
+	testValue := int16(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(testValue) // Operant: testValue
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt16(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPopInt16(t *testing.T) {
+	testValue := int16(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF0C)   // Opcode: pop-int16
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGetInt16(t *testing.T) {
+	testAddress := int(0x0C)
+	testValue := int16(-325)
+
+	p := NewProgram()
+	p.WriteByte(0x09)       // Opcode: push-int
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteOpcode(0xFF10)   // Opcode: get-int16
+	p.WriteByte(0x00)       // Opcode: end
+	p.WriteInt16(testValue) // Data: testValue
+
+	s := NewBuffer()
+	s.WriteInt16(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGetInt16Address(t *testing.T) {
+	testAddress := int(0x0B)
+	testValue := int16(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF20)   // Opcode: get-int16()
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+	p.WriteInt16(testValue) // Data: testValue
+
+	s := NewBuffer()
+	s.WriteInt16(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPutInt16(t *testing.T) {
+	testAddress := int(14 + Int16Size)
+	testValue := int16(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(testValue) // Operant: testValue
+	p.WriteByte(0x09)       // Opcode: push-int
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteOpcode(0xFF18)   // Opcode: put-int16
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+
+	m := NewBuffer()
+	m.Copy(&p.Buffer)
+	m.WriteInt16(testValue)
+
+	err := p.Run(s, m)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPutInt16Address(t *testing.T) {
+	testAddress := int(13 + Int16Size)
+	testValue := int16(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF28)   // Opcode: put-int16()
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+
+	m := NewBuffer()
+	m.Copy(&p.Buffer)
+	m.WriteInt16(testValue)
+
+	err := p.Run(s, m)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGetInt16Stack(t *testing.T) {
+	testAddress := -Int16Size
+	testValue := int16(-332)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF30)   // Opcode: get-int16{}
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt16(testValue)
+	s.WriteInt16(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPutInt16Stack(t *testing.T) {
+	testAddress := -Int16Size
+	testValue := int16(-332)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(0)         // Operant: <empty>
+	p.WriteOpcode(0xFF08)   // Opcode: push-int16
+	p.WriteInt16(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF38)   // Opcode: put-int16{}
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt16(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestInt16Arithmetic(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value1 int16
+		value2 int16
+		result int16
+	}{
+		{0xFF40, 4, 6, 10},                        // add-int16
+		{0xFF40, math.MaxInt16, 1, math.MinInt16}, // wraps
+		{0xFF44, 12, 6, 6},                        // sub-int16
+		{0xFF44, math.MinInt16, 1, math.MaxInt16},
+		{0xFF48, 12, -6, -72},               // mul-int16
+		{0xFF4C, -12, 5, -2},                // div-int16
+		{0xFF4E, -12, 2, math.MaxInt16 - 5}, // div-uint16
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteOpcode(0xFF08)      // Opcode: push-int16
+		p.WriteInt16(test.value1)  // Operant: value1
+		p.WriteOpcode(0xFF08)      // Opcode: push-int16
+		p.WriteInt16(test.value2)  // Operant: value2
+		p.WriteOpcode(test.opcode) // Opcode: the operation
+		p.WriteByte(0x00)          // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt16(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%04X %d %d: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestInt16Compare(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value1 int16
+		value2 int16
+		result byte
+	}{
+		{0xFF60, 325, 325, 0xFF}, // equal-int16
+		{0xFF60, 325, 100, 0x00},
+		{0xFF64, 325, 100, 0xFF}, // unequal-int16
+		{0xFF64, 325, 325, 0x00},
+		{0xFF68, 700, 325, 0xFF}, // greater-int16
+		{0xFF68, -1, 325, 0x00},
+		{0xFF6A, -1, 325, 0xFF}, // greater-uint16
+		{0xFF6A, 100, 325, 0x00},
+		{0xFF6C, -1, 325, 0xFF}, // smaller-int16
+		{0xFF6C, 325, 325, 0x00},
+		{0xFF6E, 100, -1, 0xFF}, // smaller-uint16
+		{0xFF6E, -1, 100, 0x00},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteOpcode(0xFF08)      // Opcode: push-int16
+		p.WriteInt16(test.value1)  // Operant: value1
+		p.WriteOpcode(0xFF08)      // Opcode: push-int16
+		p.WriteInt16(test.value2)  // Operant: value2
+		p.WriteOpcode(test.opcode) // Opcode: the comparison
+		p.WriteByte(0x00)          // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%04X %d %d: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestDivInt16Fault(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value1 int16
+		value2 int16
+		kind   error
+	}{
+		{0xFF4C, 12, 0, ErrDivisionByZero},
+		{0xFF4E, 12, 0, ErrDivisionByZero},
+		{0xFF4C, math.MinInt16, -1, ErrIntegerOverflow},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteOpcode(0xFF08)      // Opcode: push-int16
+		p.WriteInt16(test.value1)  // Operant: value1
+		p.WriteOpcode(0xFF08)      // Opcode: push-int16
+		p.WriteInt16(test.value2)  // Operant: value2
+		p.WriteOpcode(test.opcode) // Opcode: div-int16 or div-uint16
+		p.WriteByte(0x00)          // Opcode: end
+
+		err := p.Run(nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("Expected: %v, got %v", test.kind, err)
+			continue
+		}
+
+		var vmErr *VMError
+		if !errors.As(err, &vmErr) || vmErr.ProgramPointer != 2*(2+Int16Size) || vmErr.Opcode != test.opcode {
+			t.Errorf("Expected fault at %04X, got %v", test.opcode, err)
+		}
+	}
+}
+
+func TestInt16OverflowTrap(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.IntegerOverflow = IntegerOverflowTrap
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08)       // Opcode: push-int16
+	p.WriteInt16(math.MaxInt16) // Operant: math.MaxInt16
+	p.WriteOpcode(0xFF08)       // Opcode: push-int16
+	p.WriteInt16(2)             // Operant: 2
+	p.WriteOpcode(0xFF48)       // Opcode: mul-int16
+	p.WriteByte(0x00)           // Opcode: end
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("Expected: integer overflow, got %v", err)
+	}
+}
+
+func TestInt16Conversions(t *testing.T) {
+	// Widening
+	p := NewProgram()
+	p.WriteOpcode(0xFF08) // Opcode: push-int16
+	p.WriteInt16(-2)      // Operant: -2
+	p.WriteOpcode(0xFF80) // Opcode: int16-to-int
+	p.WriteOpcode(0xFF08) // Opcode: push-int16
+	p.WriteInt16(-2)      // Operant: -2
+	p.WriteOpcode(0xFF82) // Opcode: uint16-to-int
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(0xFE)     // Operant: -2
+	p.WriteOpcode(0xFF86) // Opcode: int8-to-int
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(0xFE)     // Operant: -2
+	p.WriteOpcode(0xFF88) // Opcode: int8-to-int16
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(0xFE)     // Operant: 254
+	p.WriteOpcode(0xFF8A) // Opcode: byte-to-int16
+	p.WriteByte(0x00)     // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(-2)
+	s.WriteInt(0xFFFE)
+	s.WriteInt(-2)
+	s.WriteInt16(-2)
+	s.WriteInt16(254)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Narrowing
+	p = NewProgram()
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(0x12345)   // Operant: 0x12345
+	p.WriteOpcode(0xFF84) // Opcode: int-to-int16
+	p.WriteOpcode(0xFF08) // Opcode: push-int16
+	p.WriteInt16(0x1234)  // Operant: 0x1234
+	p.WriteOpcode(0xFF8C) // Opcode: int16-to-byte
+	p.WriteByte(0x00)     // Opcode: end
+
+	s = NewBuffer()
+	s.WriteInt16(0x2345)
+	s.WriteByte(0x34)
+
+	err = p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestInt16Disabled(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Extensions = ExtensionBase
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF08) // Opcode: push-int16
+	p.WriteInt16(1)       // Operant: 1
+	p.WriteByte(0x00)     // Opcode: end
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrUnknownOpcode) {
+		t.Errorf("Expected: unknown opcode, got %v", err)
+	}
+}
@@ -0,0 +1,587 @@
+package virtualmachine
+
+import "math"
+
+// operationPushInt32 takes the following 4 bytes from memory and pushes them as an int32
+func (vm *VirtualMachine) operationPushInt32() (err error) {
+	operant, err := vm.memory.GetInt32(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(operant)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + Int32Size
+	return nil
+}
+
+// operationPopInt32 pops an int32 from stack (and looses it)
+func (vm *VirtualMachine) operationPopInt32() (err error) {
+	_, err = vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGetInt32 pops an address from stack and pushes the int32 from that memory-address
+func (vm *VirtualMachine) operationGetInt32() (err error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.memory.GetInt32(address)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationPutInt32 pops an address and pops an int32 into that memory-address
+func (vm *VirtualMachine) operationPutInt32() (err error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.memory.PutInt32(address, value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGetInt32Address takes an address and pushes the int32 from that memory-address
+func (vm *VirtualMachine) operationGetInt32Address() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.memory.GetInt32(operant)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationPutInt32Address takes an address and pops an int32 into that memory-address
+func (vm *VirtualMachine) operationPutInt32Address() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.memory.PutInt32(operant, value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationGetInt32Stack takes an offset and pushes the int32 from the memory-address stack-pointer + opperant
+func (vm *VirtualMachine) operationGetInt32Stack() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.GetInt32(operant)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationPutInt32Stack takes an offset and pops an int32 to the memory-address (stack-pointer + opperant)
+func (vm *VirtualMachine) operationPutInt32Stack() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PutInt32(operant, value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2 + IntSize
+	return nil
+}
+
+// operationAddInt32 takes 2 int32s from the stack, adds them and pushes the result
+func (vm *VirtualMachine) operationAddInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := int64(operant2) + int64(operant1)
+	if vm.integerOverflow == IntegerOverflowTrap && (result < math.MinInt32 || result > math.MaxInt32) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt32(int32(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationSubInt32 takes 2 int32s from the stack, subtracts them and pushes the result
+func (vm *VirtualMachine) operationSubInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := int64(operant2) - int64(operant1)
+	if vm.integerOverflow == IntegerOverflowTrap && (result < math.MinInt32 || result > math.MaxInt32) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt32(int32(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationMulInt32 takes 2 int32s from the stack, multiplies them and pushes the result
+func (vm *VirtualMachine) operationMulInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := int64(operant2) * int64(operant1)
+	if vm.integerOverflow == IntegerOverflowTrap && (result < math.MinInt32 || result > math.MaxInt32) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt32(int32(result))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationDivInt32 takes 2 int32s from the stack, divides them and pushes the result
+func (vm *VirtualMachine) operationDivInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+2)
+	}
+	if operant2 == math.MinInt32 && operant1 == -1 {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt32(operant2 / operant1)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationDivUint32 takes 2 int32s from the stack, divides them as unsigned values and pushes the result
+func (vm *VirtualMachine) operationDivUint32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	if operant1 == 0 {
+		return vm.fault(ErrDivisionByZero, vm.programPointer+2)
+	}
+
+	err = vm.stack.PushInt32(int32(uint32(operant2) / uint32(operant1)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationEqualInt32 takes 2 int32s from the stack, pushes FF if equal, 00 if not
+func (vm *VirtualMachine) operationEqualInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant1 == operant2 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationUnequalInt32 takes 2 int32s from the stack, pushes FF if not equal, 00 if not
+func (vm *VirtualMachine) operationUnequalInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant1 != operant2 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGreaterInt32 takes 2 int32s from the stack, pushes FF if the second one is greater, 00 if not
+func (vm *VirtualMachine) operationGreaterInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 > operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationGreaterUint32 takes 2 int32s from the stack, pushes FF if the second one is greater as unsigned values, 00 if not
+func (vm *VirtualMachine) operationGreaterUint32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if uint32(operant2) > uint32(operant1) {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationSmallerInt32 takes 2 int32s from the stack, pushes FF if the second one is smaller, 00 if not
+func (vm *VirtualMachine) operationSmallerInt32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if operant2 < operant1 {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationSmallerUint32 takes 2 int32s from the stack, pushes FF if the second one is smaller as unsigned values, 00 if not
+func (vm *VirtualMachine) operationSmallerUint32() (err error) {
+	operant1, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if uint32(operant2) < uint32(operant1) {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt32ToInt pops an int32 and pushes it as an int, extending the sign
+func (vm *VirtualMachine) operationInt32ToInt() (err error) {
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(int(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationUint32ToInt pops an int32 and pushes it as an int, extending with zeroes
+func (vm *VirtualMachine) operationUint32ToInt() (err error) {
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(int(uint32(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationIntToInt32 pops an int and pushes its lower 32 bits as an int32
+func (vm *VirtualMachine) operationIntToInt32() (err error) {
+	value, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(int32(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt8ToInt32 pops a byte and pushes it as an int32, extending the sign
+func (vm *VirtualMachine) operationInt8ToInt32() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(int32(int8(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationByteToInt32 pops a byte and pushes it as an int32, extending with zeroes
+func (vm *VirtualMachine) operationByteToInt32() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(int32(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt32ToByte pops an int32 and pushes its lower 8 bits as a byte
+func (vm *VirtualMachine) operationInt32ToByte() (err error) {
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(byte(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt16ToInt32 pops an int16 and pushes it as an int32, extending the sign
+func (vm *VirtualMachine) operationInt16ToInt32() (err error) {
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(int32(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationUint16ToInt32 pops an int16 and pushes it as an int32, extending with zeroes
+func (vm *VirtualMachine) operationUint16ToInt32() (err error) {
+	value, err := vm.stack.PopInt16()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt32(int32(uint16(value)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
+
+// operationInt32ToInt16 pops an int32 and pushes its lower 16 bits as an int16
+func (vm *VirtualMachine) operationInt32ToInt16() (err error) {
+	value, err := vm.stack.PopInt32()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt16(int16(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 2
+	return nil
+}
@@ -0,0 +1,382 @@
+package virtualmachine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPushInt32(t *testing.T) {
+	testValue := int32(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(testValue) // Operant: testValue
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt32(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPopInt32(t *testing.T) {
+	testValue := int32(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF0D)   // Opcode: pop-int32
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGetInt32(t *testing.T) {
+	testAddress := int(0x0C)
+	testValue := int32(-325)
+
+	p := NewProgram()
+	p.WriteByte(0x09)       // Opcode: push-int
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteOpcode(0xFF11)   // Opcode: get-int32
+	p.WriteByte(0x00)       // Opcode: end
+	p.WriteInt32(testValue) // Data: testValue
+
+	s := NewBuffer()
+	s.WriteInt32(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGetInt32Address(t *testing.T) {
+	testAddress := int(0x0B)
+	testValue := int32(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF21)   // Opcode: get-int32()
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+	p.WriteInt32(testValue) // Data: testValue
+
+	s := NewBuffer()
+	s.WriteInt32(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPutInt32(t *testing.T) {
+	testAddress := int(14 + Int32Size)
+	testValue := int32(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(testValue) // Operant: testValue
+	p.WriteByte(0x09)       // Opcode: push-int
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteOpcode(0xFF19)   // Opcode: put-int32
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+
+	m := NewBuffer()
+	m.Copy(&p.Buffer)
+	m.WriteInt32(testValue)
+
+	err := p.Run(s, m)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPutInt32Address(t *testing.T) {
+	testAddress := int(13 + Int32Size)
+	testValue := int32(-325)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF29)   // Opcode: put-int32()
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+
+	m := NewBuffer()
+	m.Copy(&p.Buffer)
+	m.WriteInt32(testValue)
+
+	err := p.Run(s, m)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestGetInt32Stack(t *testing.T) {
+	testAddress := -Int32Size
+	testValue := int32(-332)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF31)   // Opcode: get-int32{}
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt32(testValue)
+	s.WriteInt32(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestPutInt32Stack(t *testing.T) {
+	testAddress := -Int32Size
+	testValue := int32(-332)
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(0)         // Operant: <empty>
+	p.WriteOpcode(0xFF09)   // Opcode: push-int32
+	p.WriteInt32(testValue) // Operant: testValue
+	p.WriteOpcode(0xFF39)   // Opcode: put-int32{}
+	p.WriteInt(testAddress) // Operant: testAddress
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt32(testValue)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestInt32Arithmetic(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value1 int32
+		value2 int32
+		result int32
+	}{
+		{0xFF41, 4, 6, 10},                        // add-int32
+		{0xFF41, math.MaxInt32, 1, math.MinInt32}, // wraps
+		{0xFF45, 12, 6, 6},                        // sub-int32
+		{0xFF45, math.MinInt32, 1, math.MaxInt32},
+		{0xFF49, 12, -6, -72},               // mul-int32
+		{0xFF4D, -12, 5, -2},                // div-int32
+		{0xFF4F, -12, 2, math.MaxInt32 - 5}, // div-uint32
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteOpcode(0xFF09)      // Opcode: push-int32
+		p.WriteInt32(test.value1)  // Operant: value1
+		p.WriteOpcode(0xFF09)      // Opcode: push-int32
+		p.WriteInt32(test.value2)  // Operant: value2
+		p.WriteOpcode(test.opcode) // Opcode: the operation
+		p.WriteByte(0x00)          // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt32(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%04X %d %d: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestInt32Compare(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value1 int32
+		value2 int32
+		result byte
+	}{
+		{0xFF61, 325, 325, 0xFF}, // equal-int32
+		{0xFF61, 325, 100, 0x00},
+		{0xFF65, 325, 100, 0xFF}, // unequal-int32
+		{0xFF65, 325, 325, 0x00},
+		{0xFF69, 700, 325, 0xFF}, // greater-int32
+		{0xFF69, -1, 325, 0x00},
+		{0xFF6B, -1, 325, 0xFF}, // greater-uint32
+		{0xFF6B, 100, 325, 0x00},
+		{0xFF6D, -1, 325, 0xFF}, // smaller-int32
+		{0xFF6D, 325, 325, 0x00},
+		{0xFF6F, 100, -1, 0xFF}, // smaller-uint32
+		{0xFF6F, -1, 100, 0x00},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteOpcode(0xFF09)      // Opcode: push-int32
+		p.WriteInt32(test.value1)  // Operant: value1
+		p.WriteOpcode(0xFF09)      // Opcode: push-int32
+		p.WriteInt32(test.value2)  // Operant: value2
+		p.WriteOpcode(test.opcode) // Opcode: the comparison
+		p.WriteByte(0x00)          // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%04X %d %d: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestDivInt32Fault(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value1 int32
+		value2 int32
+		kind   error
+	}{
+		{0xFF4D, 12, 0, ErrDivisionByZero},
+		{0xFF4F, 12, 0, ErrDivisionByZero},
+		{0xFF4D, math.MinInt32, -1, ErrIntegerOverflow},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteOpcode(0xFF09)      // Opcode: push-int32
+		p.WriteInt32(test.value1)  // Operant: value1
+		p.WriteOpcode(0xFF09)      // Opcode: push-int32
+		p.WriteInt32(test.value2)  // Operant: value2
+		p.WriteOpcode(test.opcode) // Opcode: div-int32 or div-uint32
+		p.WriteByte(0x00)          // Opcode: end
+
+		err := p.Run(nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("Expected: %v, got %v", test.kind, err)
+			continue
+		}
+
+		var vmErr *VMError
+		if !errors.As(err, &vmErr) || vmErr.ProgramPointer != 2*(2+Int32Size) || vmErr.Opcode != test.opcode {
+			t.Errorf("Expected fault at %04X, got %v", test.opcode, err)
+		}
+	}
+}
+
+func TestInt32OverflowTrap(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.IntegerOverflow = IntegerOverflowTrap
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09)       // Opcode: push-int32
+	p.WriteInt32(math.MaxInt32) // Operant: math.MaxInt32
+	p.WriteOpcode(0xFF09)       // Opcode: push-int32
+	p.WriteInt32(2)             // Operant: 2
+	p.WriteOpcode(0xFF49)       // Opcode: mul-int32
+	p.WriteByte(0x00)           // Opcode: end
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("Expected: integer overflow, got %v", err)
+	}
+}
+
+func TestInt32Conversions(t *testing.T) {
+	// Widening
+	p := NewProgram()
+	p.WriteOpcode(0xFF09) // Opcode: push-int32
+	p.WriteInt32(-2)      // Operant: -2
+	p.WriteOpcode(0xFF81) // Opcode: int32-to-int
+	p.WriteOpcode(0xFF09) // Opcode: push-int32
+	p.WriteInt32(-2)      // Operant: -2
+	p.WriteOpcode(0xFF83) // Opcode: uint32-to-int
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(0xFE)     // Operant: -2
+	p.WriteOpcode(0xFF89) // Opcode: int8-to-int32
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(0xFE)     // Operant: 254
+	p.WriteOpcode(0xFF8B) // Opcode: byte-to-int32
+	p.WriteOpcode(0xFF08) // Opcode: push-int16
+	p.WriteInt16(-2)      // Operant: -2
+	p.WriteOpcode(0xFF90) // Opcode: int16-to-int32
+	p.WriteOpcode(0xFF08) // Opcode: push-int16
+	p.WriteInt16(-2)      // Operant: -2
+	p.WriteOpcode(0xFF92) // Opcode: uint16-to-int32
+	p.WriteByte(0x00)     // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(-2)
+	s.WriteInt(0xFFFFFFFE)
+	s.WriteInt32(-2)
+	s.WriteInt32(254)
+	s.WriteInt32(-2)
+	s.WriteInt32(0xFFFE)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Narrowing
+	p = NewProgram()
+	p.WriteByte(0x09)        // Opcode: push-int
+	p.WriteInt(0x123456789)  // Operant: 0x123456789
+	p.WriteOpcode(0xFF85)    // Opcode: int-to-int32
+	p.WriteOpcode(0xFF09)    // Opcode: push-int32
+	p.WriteInt32(0x12345678) // Operant: 0x12345678
+	p.WriteOpcode(0xFF8D)    // Opcode: int32-to-byte
+	p.WriteOpcode(0xFF09)    // Opcode: push-int32
+	p.WriteInt32(0x12345678) // Operant: 0x12345678
+	p.WriteOpcode(0xFF94)    // Opcode: int32-to-int16
+	p.WriteByte(0x00)        // Opcode: end
+
+	s = NewBuffer()
+	s.WriteInt32(0x23456789)
+	s.WriteByte(0x78)
+	s.WriteInt16(0x5678)
+
+	err = p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestInt32Disabled(t *testing.T) {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Extensions = ExtensionBase
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	p := NewProgram()
+	p.WriteOpcode(0xFF09) // Opcode: push-int32
+	p.WriteInt32(1)       // Operant: 1
+	p.WriteByte(0x00)     // Opcode: end
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrUnknownOpcode) {
+		t.Errorf("Expected: unknown opcode, got %v", err)
+	}
+}
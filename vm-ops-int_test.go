@@ -1,6 +1,9 @@
 package virtualmachine
 
 import (
+	"errors"
+	"math"
+	"strconv"
 	"testing"
 )
 
@@ -440,3 +443,428 @@ func TestSmallerInt(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestDivIntFault(t *testing.T) {
+	tests := []struct {
+		value1 int
+		value2 int
+		kind   error
+	}{
+		{12, 0, ErrDivisionByZero},
+		{math.MinInt, -1, ErrIntegerOverflow},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)       // Opcode: push-int
+		p.WriteInt(test.value1) // Operant: value1
+		p.WriteByte(0x09)       // Opcode: push-int
+		p.WriteInt(test.value2) // Operant: value2
+		p.WriteByte(0x4D)       // Opcode: div-int
+		p.WriteByte(0x00)       // Opcode: end
+
+		err := p.Run(nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("Expected: %v, got %v", test.kind, err)
+			continue
+		}
+
+		var vmErr *VMError
+		if !errors.As(err, &vmErr) || vmErr.ProgramPointer != 2*(1+TypeInt.Size()) || vmErr.Opcode != 0x4D {
+			t.Errorf("Expected fault at div-int, got %v", err)
+		}
+	}
+}
+
+func TestDivIntFaultHandler(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09)   // Opcode: push-int
+	p.WriteInt(12)      // Operant: 12
+	p.WriteByte(0x09)   // Opcode: push-int
+	p.WriteInt(0)       // Operant: 0
+	p.WriteByte(0x4D)   // Opcode: div-int
+	p.WriteByte(0x00)   // Opcode: end
+	handler := p.Size() // Label: handler
+	code := handler + 1 + TypeInt.Size() + 1
+	p.WriteByte(0x29) // Opcode: put-int
+	p.WriteInt(code)  // Operant: code
+	p.WriteByte(0xE0) // Opcode: ret
+	p.WriteInt(0)     // Label: code
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = vm.SetFaultHandler(handler)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The handler stores the fault code and returns to the end right after div-int, leaving the stack empty
+	s := NewBuffer()
+
+	err = p.RunOn(vm, s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	value, err := vm.Memory().GetInt(code)
+	if err != nil || value != FaultCode(ErrDivisionByZero) {
+		t.Errorf("Expected fault code %d, got %d (%v)", FaultCode(ErrDivisionByZero), value, err)
+	}
+}
+
+func TestModInt(t *testing.T) {
+	tests := []struct {
+		value1 int
+		value2 int
+		result int
+	}{
+		{17, 5, 2},
+		{-17, 5, -2},
+		{17, -5, 2},
+		{math.MinInt, -1, 0},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)       // Opcode: push-int
+		p.WriteInt(test.value1) // Operant: value1
+		p.WriteByte(0x09)       // Opcode: push-int
+		p.WriteInt(test.value2) // Operant: value2
+		p.WriteByte(0x51)       // Opcode: mod-int
+		p.WriteByte(0x00)       // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%d %d: %s", test.value1, test.value2, err.Error())
+		}
+	}
+
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(12)    // Operant: 12
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(0)     // Operant: 0
+	p.WriteByte(0x51) // Opcode: mod-int
+	p.WriteByte(0x00) // Opcode: end
+
+	err := p.Run(nil, nil)
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected: division by zero, got %v", err)
+	}
+}
+
+func TestUnaryInt(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  int
+		result int
+		trap   bool
+	}{
+		{0x52, 325, -325, false}, // neg-int
+		{0x52, -325, 325, false},
+		{0x52, math.MinInt, math.MinInt, true},
+		{0x55, 325, 326, false}, // inc-int
+		{0x55, math.MaxInt, math.MinInt, true},
+		{0x57, 325, 324, false}, // dec-int
+		{0x57, math.MinInt, math.MaxInt, true},
+		{0x58, -325, 325, false}, // abs-int
+		{0x58, 325, 325, false},
+		{0x58, math.MinInt, math.MinInt, true},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value)   // Operant: value
+		p.WriteByte(test.opcode) // Opcode: neg-int, inc-int, dec-int or abs-int
+		p.WriteByte(0x00)        // Opcode: end
+
+		// By default it wraps
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %d: %s", test.opcode, test.value, err.Error())
+		}
+
+		// Trapped on request
+		cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+		cfg.IntegerOverflow = IntegerOverflowTrap
+
+		vm, err := NewVirtualMachineWithConfig(cfg)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		err = p.RunOn(vm, nil, nil)
+		if test.trap != errors.Is(err, ErrIntegerOverflow) {
+			t.Errorf("%02X %d: expected trap %v, got %v", test.opcode, test.value, test.trap, err)
+		}
+	}
+}
+
+func TestGreaterSmallerEqualInt(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 int
+		value2 int
+		result byte
+	}{
+		{0x5B, -325, -326, 0xFF}, // greater-equal-int
+		{0x5B, -325, -325, 0xFF},
+		{0x5B, -326, -325, 0x00},
+		{0x5E, -325, -326, 0x00}, // smaller-equal-int
+		{0x5E, -325, -325, 0xFF},
+		{0x5E, -326, -325, 0xFF},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value1)  // Operant: value1
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value2)  // Operant: value2
+		p.WriteByte(test.opcode) // Opcode: greater-equal-int or smaller-equal-int
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %d %d: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestShiftInt(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  int
+		count  byte
+		result int
+	}{
+		{0x75, 3, 4, 48}, // shl-int
+		{0x75, 3, 64, 0},
+		{0x77, -16, 2, 1<<62 - 4}, // shr-int
+		{0x77, -16, 64, 0},
+		{0x79, -16, 2, -4}, // sar-int
+		{0x79, -16, 64, -1},
+		{0x79, 16, 255, 0},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value)   // Operant: value
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.count)  // Operant: count
+		p.WriteByte(test.opcode) // Opcode: shl-int, shr-int or sar-int
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %d %d: %s", test.opcode, test.value, test.count, err.Error())
+		}
+	}
+}
+
+func TestShiftIntWide(t *testing.T) {
+	// The shift works on the 64-bit int, a 32-bit host can't hold the result
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(40)   // Operant: 40
+	p.WriteByte(0x75) // Opcode: shl-int
+	p.WriteByte(0x00) // Opcode: end
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = p.RunOn(vm, nil, nil)
+	if strconv.IntSize == 32 {
+		if !errors.Is(err, ErrIntegerOverflow) {
+			t.Errorf("Expected: integer overflow, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	value, err := vm.Stack().PopInt()
+	if err != nil || int64(value) != 1<<40 {
+		t.Errorf("Expected %d, got %d (%v)", int64(1)<<40, value, err)
+	}
+}
+
+func TestBitwiseInt(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 int
+		value2 int
+		result int
+	}{
+		{0x7C, 0x0FF0, 0x3C3C, 0x0C30}, // and-int
+		{0x7D, 0x0FF0, 0x3C3C, 0x3FFC}, // or-int
+		{0x7F, 0x0FF0, 0x3C3C, 0x33CC}, // xor-int
+		{0x7C, -1, math.MinInt, math.MinInt},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value1)  // Operant: value1
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value2)  // Operant: value2
+		p.WriteByte(test.opcode) // Opcode: and-int, or-int or xor-int
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %X %X: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+
+	p := NewProgram()
+	p.WriteByte(0x09)  // Opcode: push-int
+	p.WriteInt(0x0FF0) // Operant: 0x0FF0
+	p.WriteByte(0x7E)  // Opcode: not-int
+	p.WriteByte(0x00)  // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(^0x0FF0)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestRotateInt(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  int
+		count  byte
+		result int
+	}{
+		{0x7A, math.MinInt + 1, 1, 3}, // rotl-int
+		{0x7A, 0x12, 64, 0x12},
+		{0x7A, 0x12, 68, 0x120},
+		{0x7B, 3, 1, math.MinInt + 1}, // rotr-int
+		{0x7B, 0x120, 68, 0x12},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.value)   // Operant: value
+		p.WriteByte(0x08)        // Opcode: push-byte
+		p.WriteByte(test.count)  // Operant: count
+		p.WriteByte(test.opcode) // Opcode: rotl-int or rotr-int
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %X %d: %s", test.opcode, test.value, test.count, err.Error())
+		}
+	}
+}
+
+func TestBitCountInt(t *testing.T) {
+	tests := []struct {
+		opcode Opcode
+		value  int
+		result byte
+	}{
+		{0xFF70, 0x0FF0, 8}, // popcount-int
+		{0xFF70, -1, 64},
+		{0xFF71, 0x0FF0, 52}, // clz-int
+		{0xFF71, 0, 64},
+		{0xFF71, -1, 0},
+		{0xFF72, 0x0FF0, 4}, // ctz-int
+		{0xFF72, 0, 64},
+		{0xFF72, math.MinInt, 63},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x09)          // Opcode: push-int
+		p.WriteInt(test.value)     // Operant: value
+		p.WriteOpcode(test.opcode) // Opcode: popcount-int, clz-int or ctz-int
+		p.WriteByte(0x00)          // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%04X %X: %s", test.opcode, test.value, err.Error())
+		}
+	}
+}
+
+func TestBitInt(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(0x0FF0)    // Operant: 0x0FF0
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(4)        // Operant: 4
+	p.WriteOpcode(0xFF74) // Opcode: bit-test-int
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(0x0FF0)    // Operant: 0x0FF0
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(3)        // Operant: 3
+	p.WriteOpcode(0xFF74) // Opcode: bit-test-int
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(-1)        // Operant: -1
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(64)       // Operant: 64
+	p.WriteOpcode(0xFF74) // Opcode: bit-test-int
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(0x0FF0)    // Operant: 0x0FF0
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(63)       // Operant: 63
+	p.WriteOpcode(0xFF75) // Opcode: bit-set-int
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(0x0FF0)    // Operant: 0x0FF0
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(4)        // Operant: 4
+	p.WriteOpcode(0xFF76) // Opcode: bit-clear-int
+	p.WriteByte(0x09)     // Opcode: push-int
+	p.WriteInt(0x0FF0)    // Operant: 0x0FF0
+	p.WriteByte(0x08)     // Opcode: push-byte
+	p.WriteByte(100)      // Operant: 100
+	p.WriteOpcode(0xFF75) // Opcode: bit-set-int
+	p.WriteByte(0x00)     // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0xFF)
+	s.WriteByte(0x00)
+	s.WriteByte(0x00)
+	s.WriteInt(math.MinInt | 0x0FF0)
+	s.WriteInt(0x0FE0)
+	s.WriteInt(0x0FF0)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
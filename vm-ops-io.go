@@ -0,0 +1,239 @@
+package virtualmachine
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// operationPrintByte pops a byte and writes it to the output as a decimal number
+func (vm *VirtualMachine) operationPrintByte() (err error) {
+	operant, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.write(strconv.Itoa(int(operant)))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationPrintInt pops an int and writes it to the output as a decimal number
+func (vm *VirtualMachine) operationPrintInt() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.write(strconv.Itoa(operant))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationPrintFloat pops a float and writes it to the output, in the shortest form that reads back as the same float
+func (vm *VirtualMachine) operationPrintFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.write(strconv.FormatFloat(operant, 'g', -1, 64))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationPrintChar pops a byte and writes it to the output as it is
+func (vm *VirtualMachine) operationPrintChar() (err error) {
+	operant, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.write(string([]byte{operant}))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationPrintString pops a string and writes its bytes to the output
+func (vm *VirtualMachine) operationPrintString() (err error) {
+	operant, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	err = vm.write(operant)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationReadChar reads a byte from the input and pushes it as an int, -1 at the end of the input
+func (vm *VirtualMachine) operationReadChar() (err error) {
+	result := -1
+	if vm.input != nil {
+		value, err := vm.input.ReadByte()
+		if err != nil && err != io.EOF {
+			return ioError(err)
+		}
+		if err == nil {
+			result = int(value)
+		}
+	}
+
+	err = vm.stack.PushInt(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationReadInt skips white space on the input, reads a decimal int and pushes it
+func (vm *VirtualMachine) operationReadInt() (err error) {
+	token, err := vm.readToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return vm.fault(ErrEndOfInput, vm.programPointer+1)
+	}
+
+	value, err := strconv.ParseInt(token, 10, 8*IntSize)
+	if errors.Is(err, strconv.ErrRange) || !fitsInt(value) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+	if err != nil {
+		return vm.fault(ErrInvalidConversion, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationReadFloat skips white space on the input, reads a float and pushes it
+func (vm *VirtualMachine) operationReadFloat() (err error) {
+	token, err := vm.readToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return vm.fault(ErrEndOfInput, vm.programPointer+1)
+	}
+
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return vm.fault(ErrInvalidConversion, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushFloat(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationReadLine reads a line from the input and pushes it as a new string without the line end, at the end of the
+// input it pushes -1 instead
+func (vm *VirtualMachine) operationReadLine() (err error) {
+	line := ""
+	if vm.input != nil {
+		line, err = vm.input.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return ioError(err)
+		}
+	}
+
+	if line == "" {
+		err = vm.stack.PushInt(-1)
+	} else {
+		err = vm.pushString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+	}
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// write sends text to the output, if there is one
+func (vm *VirtualMachine) write(text string) error {
+	if vm.output == nil {
+		return nil
+	}
+
+	_, err := io.WriteString(vm.output, text)
+	if err != nil {
+		return ioError(err)
+	}
+
+	return nil
+}
+
+// readToken skips white space on the input and reads up to the next white space, which is left on the input. It returns an
+// empty token at the end of the input.
+func (vm *VirtualMachine) readToken() (string, error) {
+	if vm.input == nil {
+		return "", nil
+	}
+
+	var token strings.Builder
+	for {
+		value, err := vm.input.ReadByte()
+		if err == io.EOF {
+			return token.String(), nil
+		}
+		if err != nil {
+			return "", ioError(err)
+		}
+
+		if isSpace(value) {
+			if token.Len() == 0 {
+				continue
+			}
+			return token.String(), vm.input.UnreadByte()
+		}
+		token.WriteByte(value)
+	}
+}
+
+func isSpace(value byte) bool {
+	return value == ' ' || value == '\t' || value == '\n' || value == '\r' || value == '\v' || value == '\f'
+}
+
+// ioError raises ErrIO for an error of the reader or writer
+func ioError(err error) error {
+	vmErr := newVMError(ErrIO, -1)
+	vmErr.Cause = err
+	return vmErr
+}
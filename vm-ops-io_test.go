@@ -0,0 +1,193 @@
+package virtualmachine
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// consoleMachine creates a virtual machine reading input and writing to output
+func consoleMachine(t *testing.T, input string, output *bytes.Buffer) *VirtualMachine {
+	cfg := DefaultConfig(MEMORY_SIZE, STACK_SIZE)
+	cfg.Input = strings.NewReader(input)
+	cfg.Output = output
+
+	vm, err := NewVirtualMachineWithConfig(cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return vm
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestPrint(t *testing.T) {
+	p, addresses := stringProgram(" and ")
+	p.WriteByte(0x08)        // Opcode: push-byte
+	p.WriteByte(0xFE)        // Operant: 254
+	p.WriteByte(0xD0)        // Opcode: print-byte
+	p.WriteByte(0x08)        // Opcode: push-byte
+	p.WriteByte(' ')         // Operant: ' '
+	p.WriteByte(0xD3)        // Opcode: print-char
+	p.WriteByte(0x09)        // Opcode: push-int
+	p.WriteInt(-1234)        // Operant: -1234
+	p.WriteByte(0xD1)        // Opcode: print-int
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of " and "
+	p.WriteByte(0xD4)        // Opcode: print-string
+	p.WriteByte(0x0A)        // Opcode: push-float
+	p.WriteFloat(0.1)        // Operant: 0.1
+	p.WriteByte(0xD2)        // Opcode: print-float
+	p.WriteByte(0x00)        // Opcode: end
+
+	var output bytes.Buffer
+	err := p.RunOn(consoleMachine(t, "", &output), NewBuffer(), nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if output.String() != "254 -1234 and 0.1" {
+		t.Errorf("Expected: \"254 -1234 and 0.1\", got %q", output.String())
+	}
+
+	// Without output it is thrown away
+	err = p.Run(NewBuffer(), nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// Failing output
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	vm.SetOutput(failingWriter{})
+
+	err = p.RunOn(vm, nil, nil)
+	if !errors.Is(err, ErrIO) {
+		t.Errorf("Expected: input/output error, got %v", err)
+	}
+}
+
+func TestReadChar(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xD8) // Opcode: read-char
+	p.WriteByte(0xD8) // Opcode: read-char
+	p.WriteByte(0xD8) // Opcode: read-char
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt('h')
+	s.WriteInt(0xC3) // first byte of é
+	s.WriteInt(0xA9)
+
+	err := p.RunOn(consoleMachine(t, "hé", nil), s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// At the end of input, or without input
+	s = NewBuffer()
+	s.WriteInt(-1)
+	s.WriteInt(-1)
+	s.WriteInt(-1)
+
+	err = p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestReadNumbers(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0xD9) // Opcode: read-int
+	p.WriteByte(0xDA) // Opcode: read-float
+	p.WriteByte(0xD9) // Opcode: read-int
+	p.WriteByte(0xD8) // Opcode: read-char
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(-12)
+	s.WriteFloat(2.5)
+	s.WriteInt(7)
+	s.WriteInt('\n') // white space after a number is left on the input
+
+	err := p.RunOn(consoleMachine(t, "  -12\n\t2.5e0 7\n", nil), s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	tests := []struct {
+		opcode byte
+		input  string
+		kind   error
+	}{
+		{0xD9, "twelve", ErrInvalidConversion},
+		{0xD9, "99999999999999999999", ErrIntegerOverflow},
+		{0xD9, "  \n ", ErrEndOfInput},
+		{0xDA, "2,5", ErrInvalidConversion},
+		{0xDA, "", ErrEndOfInput},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(test.opcode) // Opcode: read-int or read-float
+		p.WriteByte(0x00)        // Opcode: end
+
+		err := p.RunOn(consoleMachine(t, test.input, nil), nil, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%02X %q: expected %v, got %v", test.opcode, test.input, test.kind, err)
+		}
+	}
+
+	// Too large becomes infinite
+	p = NewProgram()
+	p.WriteByte(0xDA) // Opcode: read-float
+	p.WriteByte(0x00) // Opcode: end
+
+	s = NewBuffer()
+	s.WriteFloat(math.Inf(-1))
+
+	err = p.RunOn(consoleMachine(t, "-1e999", nil), s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	vm := consoleMachine(t, "first line\r\n\nlast", nil)
+
+	p := NewProgram()
+	p.WriteByte(0xDC) // Opcode: read-line
+	p.WriteByte(0x00) // Opcode: end
+
+	for _, expected := range []string{"first line", "", "last"} {
+		err := p.RunOn(vm, nil, nil)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		value, err := vm.popString()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if value != expected {
+			t.Errorf("Expected %q, got %q", expected, value)
+		}
+	}
+
+	// At the end of input
+	s := NewBuffer()
+	s.WriteInt(-1)
+
+	err := p.RunOn(vm, s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
@@ -0,0 +1,317 @@
+package virtualmachine
+
+import "math"
+
+// operationSqrtFloat takes a float from the stack and pushes its square root, NaN for negative numbers
+func (vm *VirtualMachine) operationSqrtFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Sqrt(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationExpFloat takes a float from the stack and pushes e to the power of it
+func (vm *VirtualMachine) operationExpFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Exp(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationLogFloat takes a float from the stack and pushes its natural logarithm, -Inf for 0 and NaN for negative numbers
+func (vm *VirtualMachine) operationLogFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Log(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationPowFloat takes an exponent and a base from the stack and pushes the base to the power of the exponent
+func (vm *VirtualMachine) operationPowFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Pow(operant2, operant1)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSinFloat takes an angle in radians from the stack and pushes its sine
+func (vm *VirtualMachine) operationSinFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Sin(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationCosFloat takes an angle in radians from the stack and pushes its cosine
+func (vm *VirtualMachine) operationCosFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Cos(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationTanFloat takes an angle in radians from the stack and pushes its tangent
+func (vm *VirtualMachine) operationTanFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Tan(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationAtan2Float takes x and y from the stack and pushes the angle of the point (x, y) in radians, between -Pi and Pi
+func (vm *VirtualMachine) operationAtan2Float() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Atan2(operant2, operant1)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFloorFloat takes a float from the stack and pushes the greatest integer value less than or equal to it
+func (vm *VirtualMachine) operationFloorFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Floor(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationCeilFloat takes a float from the stack and pushes the least integer value greater than or equal to it
+func (vm *VirtualMachine) operationCeilFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Ceil(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationTruncFloat takes a float from the stack and pushes its integer part
+func (vm *VirtualMachine) operationTruncFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Trunc(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRoundFloat takes a float from the stack and pushes the nearest integer value, halves away from zero
+func (vm *VirtualMachine) operationRoundFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Round(operant)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationMinFloat takes 2 floats from the stack and pushes the smaller one, NaN if either is NaN
+func (vm *VirtualMachine) operationMinFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Min(operant2, operant1)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationMaxFloat takes 2 floats from the stack and pushes the greater one, NaN if either is NaN
+func (vm *VirtualMachine) operationMaxFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := math.Max(operant2, operant1)
+
+	err = vm.stack.PushFloat(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIsNaNFloat takes a float from the stack, pushes FF if it is NaN, 00 if not
+func (vm *VirtualMachine) operationIsNaNFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if math.IsNaN(operant) {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIsInfFloat takes a float from the stack, pushes FF if it is +Inf or -Inf, 00 if not
+func (vm *VirtualMachine) operationIsInfFloat() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	result := byte(0x00)
+	if math.IsInf(operant, 0) {
+		result = byte(0xFF)
+	}
+
+	err = vm.stack.PushByte(result)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
@@ -0,0 +1,118 @@
+package virtualmachine
+
+import (
+	"math"
+	"testing"
+)
+
+// runMath runs opcode on the operants and checks the float it pushes, a NaN is checked with isnan-float as its bits may vary
+func runMath(t *testing.T, opcode byte, operants []float64, result float64) {
+	p := NewProgram()
+	for _, operant := range operants {
+		p.WriteByte(0x0A)     // Opcode: push-float
+		p.WriteFloat(operant) // Operant: operant
+	}
+	p.WriteByte(opcode) // Opcode: math instruction
+
+	s := NewBuffer()
+	if math.IsNaN(result) {
+		p.WriteByte(0xAE) // Opcode: isnan-float
+		s.WriteByte(0xFF)
+	} else {
+		s.WriteFloat(result)
+	}
+	p.WriteByte(0x00) // Opcode: end
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf("%02X %v: %s", opcode, operants, err.Error())
+	}
+}
+
+func TestMathUnary(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  float64
+		result float64
+	}{
+		{0xA0, 6.25, 2.5}, // sqrt-float
+		{0xA0, -1, math.NaN()},
+		{0xA0, math.Inf(1), math.Inf(1)},
+		{0xA1, 0, 1}, // exp-float
+		{0xA1, math.Inf(-1), 0},
+		{0xA2, 1, 0}, // log-float
+		{0xA2, 0, math.Inf(-1)},
+		{0xA2, -1, math.NaN()},
+		{0xA4, 0, 0}, // sin-float
+		{0xA4, math.Inf(1), math.NaN()},
+		{0xA5, 0, 1},     // cos-float
+		{0xA6, 0, 0},     // tan-float
+		{0xA8, -2.5, -3}, // floor-float
+		{0xA9, -2.5, -2}, // ceil-float
+		{0xAA, -2.5, -2}, // trunc-float
+		{0xAB, -2.5, -3}, // round-float
+		{0xAB, 2.5, 3},
+		{0xAB, math.NaN(), math.NaN()},
+		{0xA8, math.Inf(-1), math.Inf(-1)},
+	}
+
+	for _, test := range tests {
+		runMath(t, test.opcode, []float64{test.value}, test.result)
+	}
+}
+
+func TestMathBinary(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 float64
+		value2 float64
+		result float64
+	}{
+		{0xA3, 2, 10, 1024}, // pow-float
+		{0xA3, -8, 1.0 / 3, math.NaN()},
+		{0xA3, math.NaN(), 0, 1},
+		{0xA7, 1, 1, math.Pi / 4}, // atan2-float
+		{0xA7, 0, -1, math.Pi},
+		{0xAC, 1.5, -2.5, -2.5}, // min-float
+		{0xAC, 1.5, math.NaN(), math.NaN()},
+		{0xAC, math.Inf(-1), 1.5, math.Inf(-1)},
+		{0xAD, 1.5, -2.5, 1.5}, // max-float
+		{0xAD, math.NaN(), 1.5, math.NaN()},
+	}
+
+	for _, test := range tests {
+		runMath(t, test.opcode, []float64{test.value1, test.value2}, test.result)
+	}
+}
+
+func TestMathClassify(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  float64
+		result byte
+	}{
+		{0xAE, math.NaN(), 0xFF}, // isnan-float
+		{0xAE, math.Inf(1), 0x00},
+		{0xAE, 2.5, 0x00},
+		{0xAF, math.Inf(1), 0xFF}, // isinf-float
+		{0xAF, math.Inf(-1), 0xFF},
+		{0xAF, math.NaN(), 0x00},
+		{0xAF, math.MaxFloat64, 0x00},
+	}
+
+	for _, test := range tests {
+		p := NewProgram()
+		p.WriteByte(0x0A)        // Opcode: push-float
+		p.WriteFloat(test.value) // Operant: value
+		p.WriteByte(test.opcode) // Opcode: isnan-float or isinf-float
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteByte(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %g: %s", test.opcode, test.value, err.Error())
+		}
+	}
+}
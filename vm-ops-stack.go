@@ -0,0 +1,411 @@
+package virtualmachine
+
+// operationDupByte pushes a copy of the topmost byte
+func (vm *VirtualMachine) operationDupByte() (err error) {
+	value, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(value)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationDupInt pushes a copy of the topmost int
+func (vm *VirtualMachine) operationDupInt() (err error) {
+	value, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(value)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationDupFloat pushes a copy of the topmost float
+func (vm *VirtualMachine) operationDupFloat() (err error) {
+	value, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(value)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSwapByte exchanges the two topmost bytes
+func (vm *VirtualMachine) operationSwapByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSwapInt exchanges the two topmost ints
+func (vm *VirtualMachine) operationSwapInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSwapFloat exchanges the two topmost floats
+func (vm *VirtualMachine) operationSwapFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationOverByte pushes a copy of the byte below the topmost one
+func (vm *VirtualMachine) operationOverByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationOverInt pushes a copy of the int below the topmost one
+func (vm *VirtualMachine) operationOverInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationOverFloat pushes a copy of the float below the topmost one
+func (vm *VirtualMachine) operationOverFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRotByte moves the third byte from the top to the top
+func (vm *VirtualMachine) operationRotByte() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant3, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant3)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRotInt moves the third int from the top to the top
+func (vm *VirtualMachine) operationRotInt() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant3, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant3)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationRotFloat moves the third float from the top to the top
+func (vm *VirtualMachine) operationRotFloat() (err error) {
+	operant1, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	operant3, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant2)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushFloat(operant3)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSwapByteInt exchanges a byte on top of the stack with the int below it
+func (vm *VirtualMachine) operationSwapByteInt() (err error) {
+	operant1, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSwapIntByte exchanges an int on top of the stack with the byte below it
+func (vm *VirtualMachine) operationSwapIntByte() (err error) {
+	operant1, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.stack.PopByte()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushByte(operant2)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationDropN takes a number of bytes as operant and drops them from the stack
+func (vm *VirtualMachine) operationDropN() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 1)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.Drop(operant)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 1 + IntSize
+	return nil
+}
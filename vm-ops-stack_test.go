@@ -0,0 +1,232 @@
+package virtualmachine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDup(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x20) // Operant: 0x20
+	p.WriteByte(0x90) // Opcode: dup-byte
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(-1234) // Operant: -1234
+	p.WriteByte(0x91) // Opcode: dup-int
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(2.5) // Operant: 2.5
+	p.WriteByte(0x92) // Opcode: dup-float
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0x20)
+	s.WriteByte(0x20)
+	s.WriteInt(-1234)
+	s.WriteInt(-1234)
+	s.WriteFloat(2.5)
+	s.WriteFloat(2.5)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestSwap(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x01) // Operant: 0x01
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x02) // Operant: 0x02
+	p.WriteByte(0x94) // Opcode: swap-byte
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(2)     // Operant: 2
+	p.WriteByte(0x95) // Opcode: swap-int
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(1.5) // Operant: 1.5
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(2.5) // Operant: 2.5
+	p.WriteByte(0x96) // Opcode: swap-float
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0x02)
+	s.WriteByte(0x01)
+	s.WriteInt(2)
+	s.WriteInt(1)
+	s.WriteFloat(2.5)
+	s.WriteFloat(1.5)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestSwapMixed(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1234)  // Operant: 1234
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x20) // Operant: 0x20
+	p.WriteByte(0x97) // Opcode: swap-byte-int
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x30) // Operant: 0x30
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(5678)  // Operant: 5678
+	p.WriteByte(0x9B) // Opcode: swap-int-byte
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0x20)
+	s.WriteInt(1234)
+	s.WriteInt(5678)
+	s.WriteByte(0x30)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestOver(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x01) // Operant: 0x01
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x02) // Operant: 0x02
+	p.WriteByte(0x98) // Opcode: over-byte
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(2)     // Operant: 2
+	p.WriteByte(0x99) // Opcode: over-int
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(1.5) // Operant: 1.5
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(2.5) // Operant: 2.5
+	p.WriteByte(0x9A) // Opcode: over-float
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0x01)
+	s.WriteByte(0x02)
+	s.WriteByte(0x01)
+	s.WriteInt(1)
+	s.WriteInt(2)
+	s.WriteInt(1)
+	s.WriteFloat(1.5)
+	s.WriteFloat(2.5)
+	s.WriteFloat(1.5)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestRot(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x01) // Operant: 0x01
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x02) // Operant: 0x02
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x03) // Operant: 0x03
+	p.WriteByte(0x9C) // Opcode: rot-byte
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(1)     // Operant: 1
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(2)     // Operant: 2
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(3)     // Operant: 3
+	p.WriteByte(0x9D) // Opcode: rot-int
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(1.5) // Operant: 1.5
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(2.5) // Operant: 2.5
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(3.5) // Operant: 3.5
+	p.WriteByte(0x9E) // Opcode: rot-float
+	p.WriteByte(0x00) // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0x02)
+	s.WriteByte(0x03)
+	s.WriteByte(0x01)
+	s.WriteInt(2)
+	s.WriteInt(3)
+	s.WriteInt(1)
+	s.WriteFloat(2.5)
+	s.WriteFloat(3.5)
+	s.WriteFloat(1.5)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestDropN(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08)       // Opcode: push-byte
+	p.WriteByte(0x20)       // Operant: 0x20
+	p.WriteByte(0x09)       // Opcode: push-int
+	p.WriteInt(1234)        // Operant: 1234
+	p.WriteByte(0x08)       // Opcode: push-byte
+	p.WriteByte(0x30)       // Operant: 0x30
+	p.WriteByte(0x93)       // Opcode: drop-n
+	p.WriteInt(1 + IntSize) // Operant: a byte and an int
+	p.WriteByte(0x00)       // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte(0x20)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestDropNUnderflow(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x20) // Operant: 0x20
+	p.WriteByte(0x93) // Opcode: drop-n
+	p.WriteInt(2)     // Operant: 2
+
+	vmErr := runFault(t, p, ErrStackUnderflow)
+	if vmErr.ProgramPointer != 2 || vmErr.Opcode != 0x93 {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+}
+
+func TestDropNNegative(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x08) // Opcode: push-byte
+	p.WriteByte(0x20) // Operant: 0x20
+	p.WriteByte(0x93) // Opcode: drop-n
+	p.WriteInt(-1)    // Operant: -1
+
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = p.RunOn(vm, nil, nil)
+	var vmErr *VMError
+	if !errors.As(err, &vmErr) || !errors.Is(err, ErrIllegalOperant) || vmErr.ProgramPointer != 2 || vmErr.Address != -1 {
+		t.Fatalf("Expected: illegal operant at drop-n, got %v", err)
+	}
+
+	// The stack is still usable
+	if vm.Stack().Overflow() || vm.Stack().Underflow() {
+		t.Errorf("Expected the stack not to be blocked")
+	}
+	err = vm.Stack().Check([]byte{0x20})
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+}
@@ -0,0 +1,272 @@
+package virtualmachine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Strings are stored in memory as an int holding their length in bytes followed by the UTF-8 bytes. On the stack a string
+// is the address of its length, an int, so it can be kept in memory, passed to a function and returned like any other int.
+// Strings are never changed once created: instructions building a string put a new one on the heap.
+
+// operationPushString takes an address and pushes the string at that address
+func (vm *VirtualMachine) operationPushString() (err error) {
+	operant, err := vm.memory.GetInt(vm.programPointer + 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = vm.memory.GetString(operant)
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(operant)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer += 1 + IntSize
+	return nil
+}
+
+// operationLengthString pops a string and pushes its length in bytes
+func (vm *VirtualMachine) operationLengthString() (err error) {
+	operant, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(len(operant))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationConcatString pops 2 strings and pushes a new string with the topmost one appended to the 2nd
+func (vm *VirtualMachine) operationConcatString() (err error) {
+	operant1, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	err = vm.pushString(operant2 + operant1)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationSliceString pops an end, a start and a string, pushes a new string with the bytes from start up to end
+func (vm *VirtualMachine) operationSliceString() (err error) {
+	end, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	start, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	if start < 0 || end < start || end > len(operant) {
+		return vm.fault(ErrIndexOutOfRange, vm.programPointer+1)
+	}
+
+	err = vm.pushString(operant[start:end])
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationCompareString pops 2 strings, pushes -1, 0 or 1 when the 2nd one sorts before, equal to or after the topmost one
+func (vm *VirtualMachine) operationCompareString() (err error) {
+	operant1, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(strings.Compare(operant2, operant1))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIndexString pops a string to look for and a string to look in, pushes the byte index where it is first found or
+// -1 when it isn't
+func (vm *VirtualMachine) operationIndexString() (err error) {
+	operant1, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	operant2, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	err = vm.stack.PushInt(strings.Index(operant2, operant1))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationByteAtString pops an index and a string and pushes the byte at that index
+func (vm *VirtualMachine) operationByteAtString() (err error) {
+	index, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	operant, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	if index < 0 || index >= len(operant) {
+		return vm.fault(ErrIndexOutOfRange, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushByte(operant[index])
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationIntToString pops an int and pushes it as a new decimal string
+func (vm *VirtualMachine) operationIntToString() (err error) {
+	operant, err := vm.stack.PopInt()
+	if err != nil {
+		return err
+	}
+
+	err = vm.pushString(strconv.Itoa(operant))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationFloatToString pops a float and pushes it as a new string, in the shortest form that reads back as the same float
+func (vm *VirtualMachine) operationFloatToString() (err error) {
+	operant, err := vm.stack.PopFloat()
+	if err != nil {
+		return err
+	}
+
+	err = vm.pushString(strconv.FormatFloat(operant, 'g', -1, 64))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationStringToInt pops a string and pushes the decimal int it holds, faults when it doesn't hold one or it doesn't fit
+func (vm *VirtualMachine) operationStringToInt() (err error) {
+	operant, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	value, err := strconv.ParseInt(operant, 10, 8*IntSize)
+	if errors.Is(err, strconv.ErrRange) || !fitsInt(value) {
+		return vm.fault(ErrIntegerOverflow, vm.programPointer+1)
+	}
+	if err != nil {
+		return vm.fault(ErrInvalidConversion, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushInt(int(value))
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// operationStringToFloat pops a string and pushes the float it holds, faults when it doesn't hold one. Numbers too large
+// become +Inf or -Inf.
+func (vm *VirtualMachine) operationStringToFloat() (err error) {
+	operant, err := vm.popString()
+	if err != nil {
+		return err
+	}
+
+	value, err := strconv.ParseFloat(operant, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return vm.fault(ErrInvalidConversion, vm.programPointer+1)
+	}
+
+	err = vm.stack.PushFloat(value)
+	if err != nil {
+		return err
+	}
+
+	vm.programPointer++
+	return nil
+}
+
+// -- Support functions ---------------------------------------------------------------------------------------------------------
+
+// popString pops the address of a string and fetches the string from memory
+func (vm *VirtualMachine) popString() (string, error) {
+	address, err := vm.stack.PopInt()
+	if err != nil {
+		return "", err
+	}
+
+	return vm.memory.GetString(address)
+}
+
+// pushString puts value on the heap as a new string and pushes its address
+func (vm *VirtualMachine) pushString(value string) error {
+	address, err := vm.heap.AllocObject(IntSize+len(value), HeapString)
+	if err != nil {
+		return err
+	}
+
+	err = vm.memory.PutString(address, value)
+	if err != nil {
+		return err
+	}
+
+	return vm.stack.PushInt(address)
+}
@@ -0,0 +1,264 @@
+package virtualmachine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// stringProgram starts a program with a jump over values, it returns the program and the addresses of the values
+func stringProgram(values ...string) (*Program, []int) {
+	start := 1 + IntSize
+	for _, value := range values {
+		start += IntSize + len(value)
+	}
+
+	p := NewProgram()
+	p.WriteByte(0xE1) // Opcode: jmp()
+	p.WriteInt(start) // Operant: start
+
+	addresses := make([]int, len(values))
+	for i, value := range values {
+		addresses[i] = p.Size()
+		p.WriteString(value)
+	}
+
+	return p, addresses
+}
+
+// runString runs the program and returns the string it left on top of the stack
+func runString(p *Program) (string, error) {
+	vm, err := NewVirtualMachine(MEMORY_SIZE, STACK_SIZE)
+	if err != nil {
+		return "", err
+	}
+
+	err = p.RunOn(vm, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return vm.popString()
+}
+
+func TestPushString(t *testing.T) {
+	p, addresses := stringProgram("héllo")
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of héllo
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of héllo
+	p.WriteByte(0xB1)        // Opcode: length-string
+	p.WriteByte(0x00)        // Opcode: end
+
+	s := NewBuffer()
+	s.WriteInt(addresses[0])
+	s.WriteInt(6)
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	// A string running beyond memory is refused
+	p = NewProgram()
+	p.WriteByte(0xB0) // Opcode: push-string()
+	p.WriteInt(10)    // Operant: 10
+	p.WriteByte(0x00) // Opcode: end
+	p.WriteInt(1000)  // Data: a length of 1000 bytes
+
+	vmErr := runFault(t, p, ErrMemory)
+	if vmErr.ProgramPointer != 0 || vmErr.Address != 10 {
+		t.Errorf("Unexpected fault %v", vmErr)
+	}
+}
+
+func TestConcatString(t *testing.T) {
+	p, addresses := stringProgram("foo", "bar")
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of foo
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[1]) // Operant: address of bar
+	p.WriteByte(0xB2)        // Opcode: concat-string
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of foo
+	p.WriteByte(0xB2)        // Opcode: concat-string
+	p.WriteByte(0x00)        // Opcode: end
+
+	value, err := runString(p)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if value != "foobarfoo" {
+		t.Errorf("Expected: foobarfoo, got %q", value)
+	}
+}
+
+func TestSliceString(t *testing.T) {
+	tests := []struct {
+		start  int
+		end    int
+		result string
+		kind   error
+	}{
+		{1, 4, "ell", nil},
+		{0, 0, "", nil},
+		{5, 5, "", nil},
+		{0, 5, "hello", nil},
+		{-1, 2, "", ErrIndexOutOfRange},
+		{3, 2, "", ErrIndexOutOfRange},
+		{2, 6, "", ErrIndexOutOfRange},
+	}
+
+	for _, test := range tests {
+		p, addresses := stringProgram("hello")
+		p.WriteByte(0xB0)        // Opcode: push-string()
+		p.WriteInt(addresses[0]) // Operant: address of hello
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.start)   // Operant: start
+		p.WriteByte(0x09)        // Opcode: push-int
+		p.WriteInt(test.end)     // Operant: end
+		p.WriteByte(0xB3)        // Opcode: slice-string
+		p.WriteByte(0x00)        // Opcode: end
+
+		value, err := runString(p)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%d:%d: expected %v, got %v", test.start, test.end, test.kind, err)
+			continue
+		}
+		if value != test.result {
+			t.Errorf("%d:%d: expected %q, got %q", test.start, test.end, test.result, value)
+		}
+	}
+}
+
+func TestCompareIndexString(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value1 string
+		value2 string
+		result int
+	}{
+		{0xB4, "abc", "abd", -1}, // compare-string
+		{0xB4, "abc", "abc", 0},
+		{0xB4, "abc", "ab", 1},
+		{0xB5, "hello", "ll", 2}, // index-string
+		{0xB5, "hello", "", 0},
+		{0xB5, "hello", "lo!", -1},
+	}
+
+	for _, test := range tests {
+		p, addresses := stringProgram(test.value1, test.value2)
+		p.WriteByte(0xB0)        // Opcode: push-string()
+		p.WriteInt(addresses[0]) // Operant: address of value1
+		p.WriteByte(0xB0)        // Opcode: push-string()
+		p.WriteInt(addresses[1]) // Operant: address of value2
+		p.WriteByte(test.opcode) // Opcode: compare-string or index-string
+		p.WriteByte(0x00)        // Opcode: end
+
+		s := NewBuffer()
+		s.WriteInt(test.result)
+
+		err := p.Run(s, nil)
+		if err != nil {
+			t.Errorf("%02X %q %q: %s", test.opcode, test.value1, test.value2, err.Error())
+		}
+	}
+}
+
+func TestByteAtString(t *testing.T) {
+	p, addresses := stringProgram("hello")
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of hello
+	p.WriteByte(0x09)        // Opcode: push-int
+	p.WriteInt(1)            // Operant: 1
+	p.WriteByte(0xB6)        // Opcode: byte-at-string
+	p.WriteByte(0x00)        // Opcode: end
+
+	s := NewBuffer()
+	s.WriteByte('e')
+
+	err := p.Run(s, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	p, addresses = stringProgram("hello")
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of hello
+	p.WriteByte(0x09)        // Opcode: push-int
+	p.WriteInt(5)            // Operant: 5
+	p.WriteByte(0xB6)        // Opcode: byte-at-string
+	p.WriteByte(0x00)        // Opcode: end
+
+	runFault(t, p, ErrIndexOutOfRange)
+}
+
+func TestFormatString(t *testing.T) {
+	p := NewProgram()
+	p.WriteByte(0x09) // Opcode: push-int
+	p.WriteInt(-1234) // Operant: -1234
+	p.WriteByte(0xB8) // Opcode: int-to-string
+	p.WriteByte(0x0A) // Opcode: push-float
+	p.WriteFloat(0.1) // Operant: 0.1
+	p.WriteByte(0xB9) // Opcode: float-to-string
+	p.WriteByte(0xB2) // Opcode: concat-string
+	p.WriteByte(0x00) // Opcode: end
+
+	value, err := runString(p)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if value != "-12340.1" {
+		t.Errorf("Expected: -12340.1, got %q", value)
+	}
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		value  string
+		result func(s *Buffer)
+		kind   error
+	}{
+		{0xBA, "-1234", func(s *Buffer) { s.WriteInt(-1234) }, nil}, // string-to-int
+		{0xBA, "12x", nil, ErrInvalidConversion},
+		{0xBA, "", nil, ErrInvalidConversion},
+		{0xBA, "99999999999999999999", nil, ErrIntegerOverflow},
+		{0xBB, "2.5e3", func(s *Buffer) { s.WriteFloat(2500) }, nil}, // string-to-float
+		{0xBB, "1e999", func(s *Buffer) { s.WriteFloat(math.Inf(1)) }, nil},
+		{0xBB, "two", nil, ErrInvalidConversion},
+	}
+
+	for _, test := range tests {
+		p, addresses := stringProgram(test.value)
+		p.WriteByte(0xB0)        // Opcode: push-string()
+		p.WriteInt(addresses[0]) // Operant: address of value
+		p.WriteByte(test.opcode) // Opcode: string-to-int or string-to-float
+		p.WriteByte(0x00)        // Opcode: end
+
+		var s *Buffer
+		if test.result != nil {
+			s = NewBuffer()
+			test.result(s)
+		}
+
+		err := p.Run(s, nil)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%02X %q: expected %v, got %v", test.opcode, test.value, test.kind, err)
+		}
+	}
+}
+
+func TestStringHeapExhausted(t *testing.T) {
+	// Doubling a string until it no longer fits between the program and the stack
+	p, addresses := stringProgram("0123456789")
+	p.WriteByte(0xB0)        // Opcode: push-string()
+	p.WriteInt(addresses[0]) // Operant: address of 0123456789
+	loop := p.Size()
+	p.WriteByte(0x91) // Opcode: dup-int
+	p.WriteByte(0xB2) // Opcode: concat-string
+	p.WriteByte(0xE1) // Opcode: jmp()
+	p.WriteInt(loop)  // Operant: loop
+
+	runFault(t, p, ErrHeapExhausted)
+}